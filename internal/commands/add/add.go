@@ -0,0 +1,17 @@
+package add
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var AddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a feature to an existing project",
+	Long:  `Add cross-cutting features, such as authentication, to a project created with gozilla`,
+}
+
+func init() {
+	AddCmd.AddCommand(authCmd)
+	AddCmd.AddCommand(k8sCmd)
+	AddCmd.AddCommand(ciCmd)
+}
@@ -0,0 +1,59 @@
+package add
+
+import (
+	"fmt"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var authJWT bool
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Add authentication to the project",
+	Long: `Adds authentication with JSON Web Tokens (--jwt):
+- An auth module with register, login, refresh and me endpoints
+- bcrypt password hashing and an auth_users migration
+- A JWT issuer built from JWT_SECRET, JWT_ACCESS_TTL and JWT_REFRESH_TTL
+- A gin middleware, RequireAuth, putting the principal in the request context
+
+Modules generated with --protected then require an access token.`,
+	Args:    cobra.NoArgs,
+	Example: `  gozilla add auth --jwt`,
+	RunE:    runAddAuth,
+}
+
+func init() {
+	authCmd.Flags().BoolVar(&authJWT, "jwt", false, "Authenticate with JSON Web Tokens")
+}
+
+func runAddAuth(cmd *cobra.Command, args []string) error {
+	if !authJWT {
+		return fmt.Errorf("choose how to authenticate: --jwt")
+	}
+
+	report := output.Start(cmd)
+	report.Step("🔧", "Adding JWT authentication")
+
+	generator := generators.NewAuthGenerator()
+	if err := generator.Generate(); err != nil {
+		return fmt.Errorf("failed to add auth: %w", err)
+	}
+
+	report.Section("Endpoints:",
+		"POST /api/v1/auth/register",
+		"POST /api/v1/auth/login",
+		"POST /api/v1/auth/refresh",
+		"GET  /api/v1/auth/me",
+	)
+	report.Next(
+		"Fetch the new dependencies: go mod tidy",
+		"Set JWT_SECRET in .env (see .env.example)",
+		"Create the auth_users table: make migrate-up",
+		"Protect modules: gozilla generate module invoices --protected",
+	)
+
+	return report.Done("Authentication added successfully!")
+}
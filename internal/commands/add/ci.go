@@ -0,0 +1,48 @@
+package add
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci [" + strings.Join(generators.CIProviders, "|") + "]",
+	Short: "Add a CI pipeline to the project",
+	Long: `Generates a CI pipeline, for GitHub Actions (.github/workflows/ci.yml) or
+GitLab CI (.gitlab-ci.yml), that:
+- vets the code
+- applies, reverts and re-applies the migrations on a Postgres service
+  (make migrate-check, added to the Makefile if missing)
+- runs the tests with the race detector and reports coverage
+- builds the Docker image
+
+Projects can get the same pipeline from the start with gozilla new --ci.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: generators.CIProviders,
+	Example: `  gozilla add ci github
+  gozilla add ci gitlab`,
+	RunE: runAddCI,
+}
+
+func runAddCI(cmd *cobra.Command, args []string) error {
+	provider := strings.ToLower(strings.TrimSpace(args[0]))
+
+	report := output.Start(cmd)
+	report.Step("🔧", "Adding %s CI pipeline", provider)
+
+	generator := generators.NewCIGenerator()
+	if err := generator.Generate(provider); err != nil {
+		return fmt.Errorf("failed to add CI: %w", err)
+	}
+
+	report.Next(
+		"Check the migrations locally: DATABASE_URL=... make migrate-check",
+		"Commit and push to run the pipeline",
+	)
+
+	return report.Done("CI pipeline added successfully!")
+}
@@ -0,0 +1,52 @@
+package add
+
+import (
+	"fmt"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var k8sForce bool
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Add Kubernetes manifests to the project",
+	Long: `Generates Kustomize base manifests in deploy/k8s/base:
+- A Deployment with liveness and readiness probes on the health module,
+  resource requests and limits, and a non-root security context
+- A Service in front of it
+- A ConfigMap with every setting read in internal/infrastructure/config
+- A Secret with placeholders for DATABASE_URL and the other credentials
+
+Run it again after adding settings: the ConfigMap and Secret are rebuilt
+from the config, keeping the values already set in them. The Deployment,
+Service and kustomization are only rewritten with --force.`,
+	Args: cobra.NoArgs,
+	Example: `  gozilla add k8s
+  kubectl apply -k deploy/k8s/base`,
+	RunE: runAddK8s,
+}
+
+func init() {
+	k8sCmd.Flags().BoolVar(&k8sForce, "force", false, "Rewrite the Deployment, Service and kustomization too")
+}
+
+func runAddK8s(cmd *cobra.Command, args []string) error {
+	report := output.Start(cmd)
+	report.Step("🔧", "Generating Kubernetes manifests")
+
+	generator := generators.NewK8sGenerator()
+	if err := generator.Generate(k8sForce); err != nil {
+		return fmt.Errorf("failed to generate manifests: %w", err)
+	}
+
+	report.Next(
+		"Set the real secrets, out of git (see deploy/k8s/base/secret.yaml)",
+		"Push the image: make docker-build, then tag and push it",
+		"Deploy: kubectl apply -k deploy/k8s/base",
+	)
+
+	return report.Done("Kubernetes manifests generated successfully!")
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the project layout and container wiring",
+	Long: `Checks that the current project can be extended by gozilla:
+- Directory layout created by 'gozilla new'
+- go.mod module path matches the project's import paths
+- Every module in internal/modules is imported, declared in Container,
+  constructed in NewContainer and registered in RegisterRoutes
+- container.go does not reference modules that no longer exist, nor wire a
+  module twice
+- Protected modules are not served without a token over gRPC or GraphQL`,
+	Args:         cobra.NoArgs,
+	Example:      `  gozilla doctor`,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := output.Start(cmd)
+	report.Step("🩺", "Checking project...")
+
+	doctor := generators.NewDoctor()
+	issues, err := doctor.Check()
+	if err != nil {
+		return fmt.Errorf("failed to check project: %w", err)
+	}
+	report.Data(map[string][]generators.Issue{"issues": issues})
+
+	if len(issues) == 0 {
+		return report.Done("No issues found")
+	}
+
+	for _, issue := range issues {
+		report.Problem("%s\n   fix: %s", issue.Problem, issue.Fix)
+	}
+
+	return fmt.Errorf("found %d issue(s)", len(issues))
+}
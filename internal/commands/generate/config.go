@@ -0,0 +1,63 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configDefault  string
+	configRequired bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config [key] [type]",
+	Short: "Add a setting to the project config",
+	Long: `Adds a setting to config.Config, reads it in Load and lists it in
+.env.example. The type is one of ` + strings.Join(generators.ConfigTypes(), ", ") + `.
+
+The setting is read from the environment variable named after the key
+(max_items is MAX_ITEMS), or else from config.<ENVIRONMENT>.yaml and
+config.yaml. A key such as orders.max_items goes in the orders section,
+config.OrdersConfig, which the orders module can take in NewOrdersModule.
+
+With --required, Load fails when the setting is not set; such a setting has
+no default, so --required and --default cannot be combined.`,
+	Args: cobra.ExactArgs(2),
+	Example: `  gozilla generate config orders.max_items int --default 100
+  gozilla g config payments.api_key string --required
+  gozilla g config feature_flags list --default beta,search`,
+	RunE: runGenerateConfig,
+}
+
+func init() {
+	configCmd.Flags().StringVar(&configDefault, "default", "", "Default value of the setting")
+	configCmd.Flags().BoolVar(&configRequired, "required", false, "Fail at startup when the setting is not set")
+}
+
+func runGenerateConfig(cmd *cobra.Command, args []string) error {
+	key, kind := strings.TrimSpace(args[0]), strings.ToLower(strings.TrimSpace(args[1]))
+
+	report := output.Start(cmd)
+	report.Step("🔧", "Adding config setting: %s (%s)", key, kind)
+
+	generator := generators.NewConfigGenerator()
+	setting, err := generator.Generate(key, kind, generators.ConfigOptions{
+		Default:  configDefault,
+		Required: configRequired,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add config setting: %w", err)
+	}
+
+	report.Next(
+		fmt.Sprintf("Set %s in .env or in config.yaml", setting.Env),
+		fmt.Sprintf("Use cfg.%s where it is needed", setting.Field),
+	)
+
+	return report.Done("Setting cfg.%s added, read from %s", setting.Field, setting.Env)
+}
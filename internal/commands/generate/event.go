@@ -0,0 +1,56 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var eventSubscribers []string
+
+var eventCmd = &cobra.Command{
+	Use:     "event [module] [name]",
+	Aliases: []string{"ev", "e"},
+	Short:   "Generate a domain event published by a module",
+	Long: `Declares a custom event in the module's domain/events.go. Publish it
+from a use case with the module's events.Publisher; it is stored in the
+outbox in the same transaction as the change and relayed to subscribers.
+
+Each module given with --subscriber gets a handler in
+application/subscribers/, subscribed in its RegisterSubscribers, which the
+container calls at startup.`,
+	Args: cobra.ExactArgs(2),
+	Example: `  gozilla generate event orders OrderShipped
+  gozilla g event orders OrderShipped --subscriber notifications,billing`,
+	RunE: runGenerateEvent,
+}
+
+func init() {
+	eventCmd.Flags().StringSliceVar(&eventSubscribers, "subscriber", []string{}, "Modules that handle the event (comma-separated)")
+}
+
+func runGenerateEvent(cmd *cobra.Command, args []string) error {
+	moduleName := strings.ToLower(strings.TrimSpace(args[0]))
+	eventName := strings.TrimSpace(args[1])
+
+	report := output.Start(cmd)
+	report.Step("🔧", "Generating event: %s.%s", moduleName, eventName)
+
+	generator := generators.NewEventGenerator()
+	if err := generator.Generate(moduleName, eventName, eventSubscribers); err != nil {
+		return fmt.Errorf("failed to generate event: %w", err)
+	}
+
+	report.Next(
+		"Add the event's fields in domain/events.go",
+		fmt.Sprintf("Publish it from a use case: publisher.Publish(ctx, domain.%s{...})", eventName),
+	)
+	if len(eventSubscribers) > 0 {
+		report.Next(fmt.Sprintf("Handle it in the subscribers' On%s", eventName))
+	}
+
+	return report.Done("Event '%s' created successfully!", eventName)
+}
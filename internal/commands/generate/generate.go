@@ -13,4 +13,8 @@ var GenerateCmd = &cobra.Command{
 
 func init() {
 	GenerateCmd.AddCommand(moduleCmd)
+	GenerateCmd.AddCommand(eventCmd)
+	GenerateCmd.AddCommand(workerCmd)
+	GenerateCmd.AddCommand(middlewareCmd)
+	GenerateCmd.AddCommand(configCmd)
 }
@@ -0,0 +1,54 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var middlewareGlobal bool
+
+var middlewareCmd = &cobra.Command{
+	Use:     "middleware [name]",
+	Aliases: []string{"mw"},
+	Short:   "Generate an HTTP middleware",
+	Long: `Generates a gin middleware in internal/infrastructure/http/middleware,
+next to the standard ones (request ID, access log, recovery, CORS, security
+headers, body limit and timeout).
+
+With --global it is also registered in setupRoutes after the standard
+middleware, so that every route runs through it. Otherwise use it on a
+group or route: r.Group("/x", middleware.<Name>()).`,
+	Args: cobra.ExactArgs(1),
+	Example: `  gozilla generate middleware rate-limit
+  gozilla g mw tenant --global`,
+	RunE: runGenerateMiddleware,
+}
+
+func init() {
+	middlewareCmd.Flags().BoolVar(&middlewareGlobal, "global", false, "Run the middleware on every route")
+}
+
+func runGenerateMiddleware(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSpace(args[0])
+
+	report := output.Start(cmd)
+	report.Step("🔧", "Generating middleware: %s", name)
+
+	generator := generators.NewMiddlewareGenerator()
+	path, err := generator.Generate(name, middlewareGlobal)
+	if err != nil {
+		return fmt.Errorf("failed to generate middleware: %w", err)
+	}
+
+	report.Next(fmt.Sprintf("Implement the middleware in %s", path))
+	if !middlewareGlobal {
+		report.Next(`Use it on a group or route, e.g. r.Group("/x", middleware.<Name>())`)
+	}
+	report.Next("Run: make run")
+
+	return report.Done("Middleware '%s' created successfully!", name)
+}
@@ -4,14 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
 	moduleDependencies []string
+	moduleTransports   []string
+	moduleProtected    bool
+	moduleAuthz        string
 )
 
 var moduleCmd = &cobra.Command{
@@ -22,18 +27,41 @@ var moduleCmd = &cobra.Command{
 - Domain layer (entity, repository interface, errors)
 - Application layer (DTOs, use cases)
 - Infrastructure layer (handlers, repository impl, routes)
+- A migration creating the module's table
 - Tests for each layer
 - Module DI file
-- Auto-updates container.go`,
+- Auto-updates container.go
+
+With --transport grpc the module also gets a .proto service definition and
+a gRPC server adapter, and the project a gRPC server started from main.go.
+
+With --transport graphql the module gets a GraphQL schema fragment and
+resolvers, merged with the other modules' into one schema at /graphql.
+
+With --protected every HTTP route of the module requires an access token
+(see ` + "`gozilla add auth --jwt`" + `). Only the http transport checks
+tokens, so --protected and --authz cannot be combined with grpc or graphql.
+
+With --authz each route also checks a "<module>:<action>" permission, granted
+to the given roles in internal/domain/security/permissions.go. Actions are
+create, get, list, update and delete; those left out are granted to no role
+and denied until you add roles to the registry. --authz implies --protected.`,
 	Args: cobra.ExactArgs(1),
 	Example: `  gozilla generate module users
   gozilla g mod orders --depends=users
-  gozilla g m products --depends=users,categories`,
+  gozilla g m products --depends=users,categories
+  gozilla g m payments --transport http,grpc
+  gozilla g m products --transport http,graphql
+  gozilla g m invoices --protected
+  gozilla g m invoices --authz "create:admin,list:any,delete:admin|owner"`,
 	RunE: runGenerateModule,
 }
 
 func init() {
 	moduleCmd.Flags().StringSliceVar(&moduleDependencies, "depends", []string{}, "Module dependencies (comma-separated)")
+	moduleCmd.Flags().StringSliceVar(&moduleTransports, "transport", []string{"http"}, "Transports to expose the module over: http, grpc, graphql (comma-separated)")
+	moduleCmd.Flags().BoolVar(&moduleProtected, "protected", false, "Require an access token on every HTTP route of the module")
+	moduleCmd.Flags().StringVar(&moduleAuthz, "authz", "", "Roles granted each action, e.g. \"create:admin,list:any\" (implies --protected)")
 }
 
 func runGenerateModule(cmd *cobra.Command, args []string) error {
@@ -52,14 +80,9 @@ func runGenerateModule(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("module name cannot contain spaces")
 	}
 
-	// Check if we're in a gozilla project
-	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
-		return fmt.Errorf("not in a Go project directory (go.mod not found)")
-	}
-
-	modulesDir := filepath.Join("internal", "modules")
+	modulesDir := generators.ModulesDir()
 	if _, err := os.Stat(modulesDir); os.IsNotExist(err) {
-		return fmt.Errorf("not in a gozilla project (internal/modules not found)")
+		return fmt.Errorf("not in a gozilla project (%s not found)", filepath.ToSlash(modulesDir))
 	}
 
 	// Check if module already exists
@@ -68,31 +91,38 @@ func runGenerateModule(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("module '%s' already exists", moduleName)
 	}
 
-	fmt.Printf("🔧 Generating module: %s\n", moduleName)
+	report := output.Start(cmd)
+	report.Step("🔧", "Generating module: %s", moduleName)
 
 	if len(moduleDependencies) > 0 {
-		fmt.Printf("📦 Dependencies: %s\n", strings.Join(moduleDependencies, ", "))
+		report.Step("📦", "Dependencies: %s", strings.Join(moduleDependencies, ", "))
 	}
 
 	// Generate module
 	generator := generators.NewModuleGenerator()
-	if err := generator.Generate(moduleName, moduleDependencies); err != nil {
+	opts := generators.ModuleOptions{
+		Dependencies: moduleDependencies,
+		Transports:   moduleTransports,
+		Protected:    moduleProtected,
+		Authz:        moduleAuthz,
+	}
+	if err := generator.Generate(moduleName, opts); err != nil {
 		return fmt.Errorf("failed to generate module: %w", err)
 	}
 
-	fmt.Printf("\n✅ Module '%s' created successfully!\n\n", moduleName)
-	fmt.Printf("Generated files:\n")
-	fmt.Printf("  internal/modules/%s/\n", moduleName)
-	fmt.Printf("    ├── %s.module.go\n", moduleName)
-	fmt.Printf("    ├── domain/\n")
-	fmt.Printf("    ├── application/\n")
-	fmt.Printf("    └── infra/\n\n")
-	fmt.Printf("Container updated:\n")
-	fmt.Printf("  internal/infrastructure/container/container.go\n\n")
-	fmt.Printf("Next steps:\n")
-	fmt.Printf("  1. Implement business logic in domain/\n")
-	fmt.Printf("  2. Add use cases in application/\n")
-	fmt.Printf("  3. Run: make run\n")
-
-	return nil
+	grpc := slices.Contains(moduleTransports, "grpc")
+	graphql := slices.Contains(moduleTransports, "graphql")
+
+	if moduleAuthz != "" {
+		report.Next("List the permissions of the routes: gozilla routes")
+	}
+	if grpc {
+		report.Next("Generate the gRPC code: make proto (needs protoc, protoc-gen-go and protoc-gen-go-grpc)")
+	}
+	if grpc || graphql {
+		report.Next("Fetch the new dependencies: go mod tidy")
+	}
+	report.Next("Create the module's table: make migrate-up", "Implement business logic in domain/", "Add use cases in application/", "Run: make run")
+
+	return report.Done("Module '%s' created successfully!", moduleName)
 }
@@ -0,0 +1,49 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var workerCmd = &cobra.Command{
+	Use:     "worker [name]",
+	Aliases: []string{"w"},
+	Short:   "Generate a background worker",
+	Long: `Generates a worker in internal/workers/<name> that consumes the jobs
+published on its topic, and registers it in the container.
+
+The first worker also sets up:
+- A queue port in internal/domain/queue
+- An in-memory queue adapter, Container.Jobs (other brokers plug in as
+  adapters of the same port)
+- main.go running every worker next to the HTTP server and stopping them
+  on SIGINT/SIGTERM`,
+	Args: cobra.ExactArgs(1),
+	Example: `  gozilla generate worker email
+  gozilla g w thumbnails`,
+	RunE: runGenerateWorker,
+}
+
+func runGenerateWorker(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(strings.TrimSpace(args[0]))
+
+	report := output.Start(cmd)
+	report.Step("🔧", "Generating worker: %s", name)
+
+	generator := generators.NewWorkerGenerator()
+	if err := generator.Generate(name); err != nil {
+		return fmt.Errorf("failed to generate worker: %w", err)
+	}
+
+	report.Next(
+		fmt.Sprintf("Process jobs in internal/workers/%s/worker.go", name),
+		fmt.Sprintf("Enqueue jobs: container.Jobs.Publish(ctx, %s.Topic, body) (modules can take a queue.Publisher in New<X>Module)", name),
+		"Run: make run",
+	)
+
+	return report.Done("Worker '%s' created successfully!", name)
+}
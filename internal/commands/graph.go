@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show the module dependency graph",
+	Long: `Prints the dependencies between the modules in internal/modules as a
+Graphviz (DOT) or Mermaid diagram. Dependencies come from:
+- Imports between modules
+- The --depends flag each module was generated with (dashed when not imported yet)
+
+Import cycles (in red) and imports that break the domain → application → infra
+layering are reported on stderr.`,
+	Args: cobra.NoArgs,
+	Example: `  gozilla graph
+  gozilla graph --format mermaid
+  gozilla graph | dot -Tsvg > modules.svg`,
+	SilenceUsage: true,
+	RunE:         runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", "dot", "Output format (dot, mermaid)")
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	graph, err := generators.BuildModuleGraph()
+	if err != nil {
+		return fmt.Errorf("failed to build module graph: %w", err)
+	}
+
+	report := output.Start(cmd)
+	switch graphFormat {
+	case "dot":
+		report.Print(graph.DOT())
+	case "mermaid":
+		report.Print(graph.Mermaid())
+	default:
+		return fmt.Errorf("unknown format '%s' (use dot or mermaid)", graphFormat)
+	}
+
+	cycles := graph.Cycles()
+	report.Data(graph.Report())
+
+	for _, cycle := range cycles {
+		report.Problem("import cycle: %s", strings.Join(cycle, " → "))
+	}
+	for _, violation := range graph.Violations {
+		report.Warn("%s: %s (imports %s)", violation.File, violation.Reason, violation.Import)
+	}
+
+	if problems := len(cycles) + len(graph.Violations); problems > 0 {
+		return fmt.Errorf("found %d problem(s) in the module graph", problems)
+	}
+
+	return report.Done("")
+}
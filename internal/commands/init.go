@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up an existing Go project for gozilla",
+	Long: `Adopts the Go module in the current directory, so that gozilla can
+generate modules into it without running 'gozilla new':
+- Finds the file declaring a Container struct, NewContainer and a
+  RegisterRoutes method, or creates internal/infrastructure/container
+- Finds the directory holding the modules, such as internal/modules or
+  modules, or creates internal/modules
+- Pins gin and the PostgreSQL driver, which generated modules import
+- Records the container, the modules and the migrations directories in
+  .gozilla.yaml
+
+Build the container in your main with the database and logger it already
+has, then generate modules as usual.`,
+	Args:         cobra.NoArgs,
+	Example:      `  gozilla init`,
+	SilenceUsage: true,
+	RunE:         runInit,
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	report := output.Start(cmd)
+	report.Step("🔧", "Setting up the project for gozilla")
+
+	generator := generators.NewInitGenerator()
+	config, created, err := generator.Generate()
+	if err != nil {
+		return fmt.Errorf("failed to set up the project: %w", err)
+	}
+
+	report.Section("Layout:",
+		"container:  "+config.Container,
+		"modules:    "+config.Modules,
+		"migrations: "+config.Migrations,
+	)
+	report.Data(config)
+	if created {
+		report.Next("Build the container in main: c := container.NewContainer(db, logger), then c.RegisterRoutes(router)")
+	}
+	report.Next(
+		"Generate a module: gozilla generate module users",
+		"Fetch the new dependencies: go mod tidy",
+	)
+
+	return report.Done("Project set up for gozilla!")
+}
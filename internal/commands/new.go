@@ -2,11 +2,14 @@ package commands
 
 import (
 	"fmt"
+	goversion "go/version"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -19,25 +22,90 @@ var newCmd = &cobra.Command{
 - PostgreSQL database setup
 - Docker Compose configuration
 - DI container skeleton
-- Example health check module`,
-	Args: cobra.ExactArgs(1),
-	Example: `  gozilla new my-api
-  gozilla new github.com/myuser/my-project`,
+- Example health check module
+
+With --observability the project also gets OpenTelemetry tracing (spans per
+request, use case and query) and Prometheus metrics on /metrics.
+
+With --ci github or --ci gitlab it gets a CI pipeline that vets and tests
+the code with the race detector and coverage, checks the migrations against
+a Postgres service and builds the Docker image.
+
+go.mod pins the dependencies to versions known to work together, and its go
+directive is the version of the local toolchain unless --go-version is set.
+It must be one the dependencies build with: 1.23.0 or later with
+--observability.
+The dependencies are then downloaded with go mod tidy; --skip-install leaves
+that for later, to create projects offline.
+
+Without a project name, or with --interactive, a wizard asks for the name,
+the options above and whether to add JWT authentication (gozilla add auth
+--jwt), shows a summary to confirm and prints the equivalent command.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `  gozilla new
+  gozilla new my-api
+  gozilla new github.com/myuser/my-project
+  gozilla new my-api --observability
+  gozilla new my-api --ci github
+  gozilla new my-api --skip-install --go-version 1.24`,
 	RunE: runNew,
 }
 
+var (
+	newObservability bool
+	newCI            string
+	newInteractive   bool
+	newSkipInstall   bool
+	newGoVersion     string
+)
+
+func init() {
+	newCmd.Flags().BoolVar(&newObservability, "observability", false, "Add OpenTelemetry tracing and Prometheus metrics")
+	newCmd.Flags().StringVar(&newCI, "ci", "", "Generate a CI pipeline: "+strings.Join(generators.CIProviders, " or "))
+	newCmd.Flags().BoolVarP(&newInteractive, "interactive", "i", false, "Choose the options in a wizard")
+	newCmd.Flags().BoolVar(&newSkipInstall, "skip-install", false, "Do not download the dependencies (go mod tidy)")
+	newCmd.Flags().StringVar(&newGoVersion, "go-version", "", "go directive of go.mod, such as 1.24, "+generators.MinimumGoVersion+" or later (default: the local toolchain's)")
+}
+
 func runNew(cmd *cobra.Command, args []string) error {
-	projectName := args[0]
+	if len(args) == 0 || newInteractive {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return runNewWizard(cmd, name)
+	}
 
+	return createProject(cmd, args[0], generators.ProjectOptions{
+		Observability: newObservability,
+		CI:            newCI,
+		GoVersion:     newGoVersion,
+		SkipInstall:   newSkipInstall,
+	})
+}
+
+// validateProject checks the name and options of a new project and returns
+// the directory it will be created in.
+func validateProject(projectName string, opts generators.ProjectOptions) (string, error) {
 	// Validate project name
 	if projectName == "" {
-		return fmt.Errorf("project name cannot be empty")
+		return "", fmt.Errorf("project name cannot be empty")
 	}
 
 	// Clean and validate project name
-	projectName = strings.TrimSpace(projectName)
 	if strings.Contains(projectName, " ") {
-		return fmt.Errorf("project name cannot contain spaces")
+		return "", fmt.Errorf("project name cannot contain spaces")
+	}
+
+	if opts.CI != "" && !slices.Contains(generators.CIProviders, opts.CI) {
+		return "", fmt.Errorf("unknown --ci '%s' (expected %s)", opts.CI, strings.Join(generators.CIProviders, " or "))
+	}
+
+	if opts.GoVersion != "" && !generators.ValidGoVersion(opts.GoVersion) {
+		return "", fmt.Errorf("invalid --go-version '%s' (expected a version such as 1.24 or 1.24.2, %s or later)", opts.GoVersion, generators.MinimumGoVersion)
+	}
+	if minimum := generators.MinimumGoVersionFor(opts.Features()...); opts.GoVersion != "" && goversion.Compare("go"+opts.GoVersion, "go"+minimum) < 0 {
+		return "", fmt.Errorf("--go-version %s is too old for %s, whose dependencies need go %s or later", opts.GoVersion, strings.Join(opts.Features(), " and "), minimum)
 	}
 
 	// Extract project directory name from full path if provided
@@ -45,30 +113,50 @@ func runNew(cmd *cobra.Command, args []string) error {
 
 	// Check if directory already exists
 	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
-		return fmt.Errorf("directory '%s' already exists", projectDir)
+		return "", fmt.Errorf("directory '%s' already exists", projectDir)
+	}
+
+	return projectDir, nil
+}
+
+func createProject(cmd *cobra.Command, projectName string, opts generators.ProjectOptions) error {
+	projectName = strings.TrimSpace(projectName)
+	projectDir, err := validateProject(projectName, opts)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("🚀 Creating new project: %s\n", projectName)
+	report := output.Start(cmd)
+	report.Step("🚀", "Creating new project: %s", projectName)
 
 	// Generate project
 	generator := generators.NewProjectGenerator()
-	if err := generator.Generate(projectName, projectDir); err != nil {
+	if err := generator.Generate(projectName, projectDir, opts); err != nil {
 		return fmt.Errorf("failed to generate project: %w", err)
 	}
 
 	// install dependencies
-	fmt.Printf("📦 Installing dependencies...\n")
-	if err := generator.InstallDependencies(projectDir); err != nil {
-		return fmt.Errorf("failed to install dependencies: %w", err)
+	report.Next("cd " + projectDir)
+	if opts.SkipInstall {
+		report.Next("Download the dependencies: go mod tidy")
+	} else {
+		report.Step("📦", "Installing dependencies...")
+		if err := generator.InstallDependencies(projectDir); err != nil {
+			return fmt.Errorf("failed to install dependencies: %w", err)
+		}
 	}
 
-	fmt.Printf("\n✅ Project created successfully!\n\n")
-	fmt.Printf("Next steps:\n")
-	fmt.Printf("  cd %s\n", projectDir)
-	fmt.Printf("  docker-compose up -d\n")
-	fmt.Printf("  make run\n\n")
-	fmt.Printf("Generate your first module:\n")
-	fmt.Printf("  gozilla generate module users\n")
+	if opts.Auth {
+		report.Next("Set JWT_SECRET in .env (see .env.example)")
+	}
+	report.Next("docker-compose up -d")
+	if opts.Auth {
+		report.Next("Create the auth_users table: make migrate-up")
+	}
+	report.Next(
+		"make run",
+		"Generate your first module: gozilla generate module users",
+	)
 
-	return nil
+	return report.Done("Project created successfully!")
 }
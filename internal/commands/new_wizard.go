@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// runNewWizard asks for the name and options of a new project, shows them
+// for confirmation along with the equivalent `gozilla new` command, and
+// creates the project.
+func runNewWizard(cmd *cobra.Command, projectName string) error {
+	if !output.IsTerminal(os.Stdin) {
+		return fmt.Errorf("no project name given: run gozilla new <project-name>, or run it in a terminal to use the wizard")
+	}
+	if output.JSON {
+		return fmt.Errorf("the wizard cannot print JSON: run gozilla new <project-name> --output json")
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	p.info("%s\n\n", output.WithIcon("🦖", "Let's set up a new project (press Enter to keep the default)"))
+
+	if projectName == "" {
+		projectName = "my-api"
+	}
+	for {
+		name, err := p.ask("Project name or module path", projectName)
+		if err != nil {
+			return err
+		}
+		if _, err := validateProject(name, generators.ProjectOptions{}); err != nil {
+			p.problem("%s", err)
+			continue
+		}
+		projectName = name
+		break
+	}
+
+	auth, err := p.confirm("Add JWT authentication (register, login and protected modules)?", false)
+	if err != nil {
+		return err
+	}
+
+	observability, err := p.confirm("Add OpenTelemetry tracing and Prometheus metrics?", newObservability)
+	if err != nil {
+		return err
+	}
+
+	ciDefault := newCI
+	if ciDefault == "" {
+		ciDefault = "none"
+	}
+	ci, err := p.choose("CI pipeline", append([]string{"none"}, generators.CIProviders...), ciDefault)
+	if err != nil {
+		return err
+	}
+	if ci == "none" {
+		ci = ""
+	}
+
+	features := generators.ProjectOptions{Auth: auth, Observability: observability}.Features()
+	defaultGoVersion := generators.DefaultGoVersion(features...)
+	goVersion := newGoVersion
+	if goVersion == "" {
+		goVersion = defaultGoVersion
+	}
+	for {
+		version, err := p.ask("Go version of go.mod", goVersion)
+		if err != nil {
+			return err
+		}
+		if _, err := validateProject(projectName, generators.ProjectOptions{GoVersion: version, Auth: auth, Observability: observability}); err != nil {
+			p.problem("%s", err)
+			continue
+		}
+		goVersion = version
+		break
+	}
+
+	install, err := p.confirm("Download the dependencies now (go mod tidy)?", !newSkipInstall)
+	if err != nil {
+		return err
+	}
+
+	opts := generators.ProjectOptions{
+		Observability: observability,
+		CI:            ci,
+		SkipInstall:   !install,
+		Auth:          auth,
+	}
+	// The default version is the generator's too; keep it out of the command
+	if goVersion != defaultGoVersion {
+		opts.GoVersion = goVersion
+	}
+
+	p.info("\nSummary:\n")
+	p.info("  Project:        %s (in ./%s)\n", projectName, filepath.Base(projectName))
+	p.info("  Stack:          Gin, PostgreSQL, Go %s\n", goVersion)
+	p.info("  Auth:           %s\n", authLabel(opts.Auth))
+	p.info("  Observability:  %s\n", yesNo(opts.Observability))
+	p.info("  CI:             %s\n", ciLabel(opts.CI))
+	p.info("  Dependencies:   %s\n", installLabel(opts.SkipInstall))
+	p.info("\nEquivalent command:\n  %s\n\n", newCommandLine(projectName, opts))
+
+	ok, err := p.confirm("Create the project?", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		p.info("Cancelled, nothing was created\n")
+		return nil
+	}
+
+	p.info("\n")
+	return createProject(cmd, projectName, opts)
+}
+
+// newCommandLine returns the `gozilla new` command creating the same
+// project as the wizard, to script it.
+func newCommandLine(projectName string, opts generators.ProjectOptions) string {
+	args := []string{"gozilla", "new", projectName}
+	if opts.Observability {
+		args = append(args, "--observability")
+	}
+	if opts.CI != "" {
+		args = append(args, "--ci", opts.CI)
+	}
+	if opts.GoVersion != "" {
+		args = append(args, "--go-version", opts.GoVersion)
+	}
+	if opts.SkipInstall {
+		args = append(args, "--skip-install")
+	}
+	line := strings.Join(args, " ")
+	if opts.Auth {
+		line += " && cd " + filepath.Base(projectName) + " && gozilla add auth --jwt"
+	}
+	return line
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func authLabel(auth bool) string {
+	if auth {
+		return "JWT"
+	}
+	return "none"
+}
+
+func installLabel(skip bool) string {
+	if skip {
+		return "downloaded later (go mod tidy)"
+	}
+	return "downloaded now"
+}
+
+func ciLabel(ci string) string {
+	if ci == "" {
+		return "none"
+	}
+	return ci
+}
+
+// prompter asks questions on a terminal. An empty answer picks the default.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// info prints the wizard's own text, which --quiet hides.
+func (p *prompter) info(format string, args ...any) {
+	if output.Quiet {
+		return
+	}
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// problem tells what is wrong with an answer, before asking again.
+func (p *prompter) problem(format string, args ...any) {
+	fmt.Fprintf(p.out, "  %s\n", output.WithIcon("✗", fmt.Sprintf(format, args...)))
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return "", fmt.Errorf("cancelled")
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (p *prompter) ask(question, defaultValue string) (string, error) {
+	fmt.Fprintf(p.out, "? %s (%s): ", question, defaultValue)
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+func (p *prompter) confirm(question string, defaultValue bool) (bool, error) {
+	hint := "y/N"
+	if defaultValue {
+		hint = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.out, "? %s [%s]: ", question, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		p.problem("answer y or n")
+	}
+}
+
+// choose asks for one of options, by name or number.
+func (p *prompter) choose(question string, options []string, defaultValue string) (string, error) {
+	fmt.Fprintf(p.out, "? %s\n", question)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+
+	for {
+		fmt.Fprintf(p.out, "  choice (%s): ", defaultValue)
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return defaultValue, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		if answer = strings.ToLower(answer); slices.Contains(options, answer) {
+			return answer, nil
+		}
+		p.problem("pick one of: %s", strings.Join(options, ", "))
+	}
+}
@@ -3,8 +3,13 @@ package commands
 import (
 	"fmt"
 	"os"
+	"slices"
 
+	"github.com/pierslabs/gozilla-cli/internal/commands/add"
 	"github.com/pierslabs/gozilla-cli/internal/commands/generate"
+	"github.com/pierslabs/gozilla-cli/internal/commands/sync"
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -16,17 +21,72 @@ var rootCmd = &cobra.Command{
 - Automatic dependency injection (via generated code)
 - Modular structure (each feature is a self-contained module)
 - Production-ready setup (Docker, PostgreSQL, tests, migrations)`,
-	Version: "0.1.0",
+	Version:           "0.1.0",
+	SilenceErrors:     true,
+	PersistentPreRunE: setup,
 }
 
+var outputFormat string
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		output.Fail(cmd, err)
 		os.Exit(1)
 	}
 }
 
+// setup applies the global output flags, NO_COLOR in the environment
+// working like --no-color. Project commands then run from the root of the
+// project, found from any of its subdirectories, with its layout loaded.
+func setup(cmd *cobra.Command, args []string) error {
+	switch outputFormat {
+	case "text":
+	case "json":
+		output.JSON = true
+		// Scripts read the error from the JSON result
+		cmd.SilenceUsage = true
+	default:
+		return fmt.Errorf("unknown --output '%s' (use text or json)", outputFormat)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		output.NoColor = true
+	}
+
+	for c := cmd; c != nil; c = c.Parent() {
+		if slices.Contains(projectCommands, c) {
+			if err := generators.EnterProject(); err != nil {
+				// The arguments are fine; the working directory is not
+				cmd.SilenceUsage = true
+				return err
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// projectCommands work on an existing project, as do their subcommands.
+var projectCommands = []*cobra.Command{
+	initCmd,
+	doctorCmd,
+	graphCmd,
+	routesCmd,
+	generate.GenerateCmd,
+	add.AddCmd,
+	sync.SyncCmd,
+}
+
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text or json (a result with the files written, warnings and next steps)")
+	rootCmd.PersistentFlags().BoolVarP(&output.Quiet, "quiet", "q", false, "Hide progress, summaries and next steps")
+	rootCmd.PersistentFlags().BoolVar(&output.NoColor, "no-color", false, "Print text without colors or emoji")
+
 	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(graphCmd)
+	rootCmd.AddCommand(routesCmd)
 	rootCmd.AddCommand(generate.GenerateCmd)
+	rootCmd.AddCommand(add.AddCmd)
+	rootCmd.AddCommand(sync.SyncCmd)
 }
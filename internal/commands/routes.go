@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List the HTTP routes and the permissions guarding them",
+	Long: `Lists the HTTP routes registered by the modules in internal/modules, with:
+- Whether the route requires an access token
+- The permission it checks (modules generated with --authz)
+- The roles granted that permission in security.Permissions
+
+Permissions that a route checks but the registry lacks (denied to everyone),
+registry entries that no route checks and gRPC or GraphQL transports serving
+a protected module without a token are reported on stderr.`,
+	Args:         cobra.NoArgs,
+	Example:      `  gozilla routes`,
+	SilenceUsage: true,
+	RunE:         runRoutes,
+}
+
+func runRoutes(cmd *cobra.Command, args []string) error {
+	table, err := generators.ListRoutes()
+	if err != nil {
+		return fmt.Errorf("failed to list routes: %w", err)
+	}
+
+	report := output.Start(cmd)
+	report.Data(table)
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tAUTH\tPERMISSION\tROLES")
+
+	var missing []string
+	for _, route := range table.Routes {
+		auth, permission, roles := "-", "-", "-"
+		if route.Protected {
+			auth = "token"
+		}
+		if route.Permission != "" {
+			permission = route.Permission
+			roles = strings.Join(route.Roles, ", ")
+			if len(route.Roles) == 0 {
+				roles = "(none)"
+			}
+			if !route.Registered {
+				roles = "(none)"
+				missing = append(missing, route.Permission)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Module+"."+strings.TrimPrefix(route.Handler, "handler."), auth, permission, roles)
+	}
+	w.Flush()
+	report.Print(out.String())
+
+	for _, permission := range missing {
+		report.Problem("permission %s is not in security.Permissions; every request is denied", permission)
+	}
+	for _, permission := range table.Unused {
+		report.Warn("permission %s is in security.Permissions but no route checks it", permission)
+	}
+
+	for _, t := range table.Unguarded {
+		report.Problem("module %s requires a token on its HTTP routes but serves %s without one", t.Module, t.Transport)
+	}
+
+	if problems := len(missing) + len(table.Unused) + len(table.Unguarded); problems > 0 {
+		return fmt.Errorf("found %d problem(s) guarding the routes", problems)
+	}
+
+	return report.Done("")
+}
@@ -0,0 +1,50 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/pierslabs/gozilla-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var containerCmd = &cobra.Command{
+	Use:   "container",
+	Short: "Rebuild the module wiring in container.go",
+	Long: `Rebuilds the module-related parts of container.go from the modules in
+internal/modules and their declared dependencies:
+- Module imports
+- Container fields
+- Module construction in NewContainer
+- Route registration in RegisterRoutes
+
+Duplicated wiring is removed, missing wiring is added and modules are
+ordered so that dependencies come first. Code that does not belong to a
+module is left untouched.`,
+	Args:    cobra.NoArgs,
+	Example: `  gozilla sync container`,
+	RunE:    runSyncContainer,
+}
+
+func runSyncContainer(cmd *cobra.Command, args []string) error {
+	modulesDir := generators.ModulesDir()
+	if _, err := os.Stat(modulesDir); os.IsNotExist(err) {
+		return fmt.Errorf("not in a gozilla project (%s not found)", filepath.ToSlash(modulesDir))
+	}
+
+	report := output.Start(cmd)
+	report.Step("🔄", "Syncing container with modules on disk...")
+
+	updater := generators.NewContainerUpdater()
+	modules, err := updater.Sync()
+	if err != nil {
+		return fmt.Errorf("failed to sync container: %w", err)
+	}
+
+	report.Section("Modules wired (in construction order):", modules...)
+	report.Data(map[string][]string{"modules": modules})
+
+	return report.Done("Container synced!")
+}
@@ -0,0 +1,15 @@
+package sync
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var SyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Rebuild generated wiring from the project on disk",
+	Long:  `Regenerate code that gozilla maintains (such as the DI container) from the modules present in the project`,
+}
+
+func init() {
+	SyncCmd.AddCommand(containerCmd)
+}
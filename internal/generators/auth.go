@@ -0,0 +1,297 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var (
+	securityPath       = filepath.Join("internal", "domain", "security", "security.go")
+	jwtPath            = filepath.Join("internal", "infrastructure", "token", "jwt.go")
+	authMiddlewarePath = filepath.Join("internal", "infrastructure", "http", "middleware", "auth.go")
+	migrationsDir      = "migrations"
+	composePath        = "docker-compose.yaml"
+)
+
+// hasAuth reports whether `gozilla add auth` has set up the project, so that
+// modules can be generated with --protected.
+func hasAuth() bool {
+	_, err := os.Stat(authMiddlewarePath)
+	return err == nil
+}
+
+type AuthGenerator struct{}
+
+func NewAuthGenerator() *AuthGenerator {
+	return &AuthGenerator{}
+}
+
+// Generate adds JWT authentication to the project: the security port, a
+// JWT token issuer built from config, the RequireAuth middleware and the
+// auth module with its register, login and refresh endpoints, plus the
+// permission registry and Authorize middleware used by --authz.
+func (g *AuthGenerator) Generate() error {
+	moduleDir := filepath.Join(modulesDir, "auth")
+	if _, err := os.Stat(moduleDir); err == nil {
+		return fmt.Errorf("module 'auth' already exists")
+	}
+	if err := checkGoVersion("auth"); err != nil {
+		return err
+	}
+
+	project, err := projectData()
+	if err != nil {
+		return err
+	}
+	data := templates.ModuleData{ModulePath: project.ModulePath, ModulesImportPath: modulesImportPath(project.ModulePath), ModuleName: "auth", ModuleNameTitle: "Auth"}
+
+	files := map[string]string{
+		securityPath:       projecttemplates.SecurityTemplate(project),
+		jwtPath:            projecttemplates.JWTTemplate(project),
+		authMiddlewarePath: projecttemplates.AuthMiddlewareTemplate(project),
+
+		permissionsPath:         projecttemplates.PermissionsTemplate(project),
+		authorizeMiddlewarePath: projecttemplates.AuthorizeMiddlewareTemplate(project),
+
+		filepath.Join(moduleDir, "auth.module.go"):                         templates.AuthModuleTemplate(data),
+		filepath.Join(moduleDir, "domain", "user.go"):                      templates.AuthUserTemplate(data),
+		filepath.Join(moduleDir, "domain", "auth.go"):                      templates.AuthDomainTemplate(data),
+		filepath.Join(moduleDir, "application", "dto", "auth_dto.go"):      templates.AuthDTOTemplate(data),
+		filepath.Join(moduleDir, "application", "usecases", "register.go"): templates.RegisterUseCaseTemplate(data),
+		filepath.Join(moduleDir, "application", "usecases", "login.go"):    templates.LoginUseCaseTemplate(data),
+		filepath.Join(moduleDir, "application", "usecases", "refresh.go"):  templates.RefreshUseCaseTemplate(data),
+		filepath.Join(moduleDir, "infra", "user_repository.go"):            templates.AuthRepositoryTemplate(data),
+		filepath.Join(moduleDir, "infra", "bcrypt_hasher.go"):              templates.BcryptHasherTemplate(data),
+		filepath.Join(moduleDir, "infra", "handler.go"):                    templates.AuthHandlerTemplate(data),
+		filepath.Join(moduleDir, "infra", "routes.go"):                     templates.AuthRoutesTemplate(data),
+	}
+
+	migration, err := nextMigration("create_auth_users")
+	if err != nil {
+		return err
+	}
+	files[migration+".up.sql"] = projecttemplates.AuthMigrationUpTemplate(project)
+	files[migration+".down.sql"] = projecttemplates.AuthMigrationDownTemplate(project)
+
+	for path, content := range files {
+		if _, err := os.Stat(path); err == nil && !strings.HasPrefix(path, moduleDir) {
+			continue
+		}
+		if strings.HasSuffix(path, ".go") {
+			if formatted, err := format.Source([]byte(content)); err == nil {
+				content = string(formatted)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(path, []byte(content)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if err := addAuthSettings(); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	if err := appendIfMissing(".env.example", "JWT_SECRET=", projecttemplates.AuthEnvExample()); err != nil {
+		return err
+	}
+	if err := addComposeEnvironment("JWT_SECRET"); err != nil {
+		return err
+	}
+	if err := requireFeature("auth"); err != nil {
+		return err
+	}
+
+	if err := provideTokens(project.ModulePath); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
+	}
+	if err := NewContainerUpdater().AddModule("auth"); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
+	}
+
+	return nil
+}
+
+// addAuthSettings adds the JWT secret and token lifetimes to the config.
+func addAuthSettings() error {
+	// Configs that validate settings refuse to start without a secret
+	secret := `getEnv("JWT_SECRET", "")`
+	if configDeclares("required") {
+		secret = `getEnv(required("JWT_SECRET"), "")`
+	}
+
+	err := addConfigGroup("Authentication",
+		configSetting{"JWTSecret", "string", secret},
+		configSetting{"JWTAccessTTL", "time.Duration", `getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute)`},
+		configSetting{"JWTRefreshTTL", "time.Duration", `getDurationEnv("JWT_REFRESH_TTL", 30*24*time.Hour)`},
+	)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config.go: %w", err)
+	}
+
+	cs, err := parseSource(configPath, src)
+	if err != nil {
+		return err
+	}
+
+	if !cs.declaresConfig("getDurationEnv") {
+		if err := cs.apply(edit{len(cs.src), len(cs.src), projecttemplates.DurationEnvHelper()}); err != nil {
+			return err
+		}
+	}
+	for _, p := range []string{"os", "time"} {
+		if err := cs.addImport(p); err != nil {
+			return err
+		}
+	}
+
+	return writeFile(configPath, cs.src)
+}
+
+// provideTokens makes NewContainer take the config, if it does not yet,
+// and build the JWT token issuer from it.
+func provideTokens(modulePath string) error {
+	src, err := os.ReadFile(containerPath)
+	if err != nil {
+		return fmt.Errorf("failed to read container.go: %w", err)
+	}
+
+	cs, err := parseContainer(src)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cs.acceptConfig(modulePath)
+	if err != nil {
+		return err
+	}
+
+	err = cs.addSharedValue(sharedValue{
+		field:      "Tokens",
+		fieldType:  "*token.JWT",
+		variable:   "tokens",
+		init:       fmt.Sprintf("token.NewJWT(%s.JWTSecret, %s.JWTAccessTTL, %s.JWTRefreshTTL)", cfg, cfg, cfg),
+		comment:    "Tokens for the auth module and protected routes",
+		importPath: modulePath + "/internal/infrastructure/token",
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeFile(containerPath, cs.src)
+}
+
+// acceptConfig adds a `cfg *config.Config` parameter to NewContainer and
+// passes the config from main.go, unless NewContainer already takes one. It
+// returns the parameter name.
+func (cs *containerSource) acceptConfig(modulePath string) (string, error) {
+	fn := cs.function("NewContainer")
+	if fn == nil {
+		return "", fmt.Errorf("%s: cannot find func NewContainer", containerPath)
+	}
+
+	for _, param := range fn.Type.Params.List {
+		if paramType, _ := paramArg(param.Type); paramType == "cfg" && len(param.Names) == 1 {
+			return param.Names[0].Name, nil
+		}
+	}
+
+	off := cs.offset(fn.Type.Params.Opening) + 1
+	text := "cfg *config.Config"
+	if len(fn.Type.Params.List) > 0 {
+		text += ", "
+	}
+	if err := cs.apply(edit{off, off, text}); err != nil {
+		return "", err
+	}
+	if err := cs.addImport(modulePath + "/internal/infrastructure/config"); err != nil {
+		return "", err
+	}
+
+	return "cfg", passConfig()
+}
+
+// passConfig adds the config as the first argument of main.go's
+// NewContainer call.
+func passConfig() error {
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		return fmt.Errorf("failed to read main.go: %w", err)
+	}
+
+	cs, err := parseSource(mainPath, src)
+	if err != nil {
+		return err
+	}
+
+	fn := cs.function("main")
+	if fn == nil {
+		return fmt.Errorf("%s: cannot find func main; pass the config to container.NewContainer by hand", mainPath)
+	}
+
+	cfg := "cfg"
+	var call *ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			// cfg, err := config.Load()
+			if len(n.Rhs) == 1 && len(n.Lhs) > 0 && strings.HasSuffix(cs.text(n.Rhs[0]), "config.Load()") {
+				if ident, ok := n.Lhs[0].(*ast.Ident); ok {
+					cfg = ident.Name
+				}
+			}
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewContainer" && call == nil {
+				call = n
+			}
+		}
+		return true
+	})
+	if call == nil {
+		return cs.errorf(fn, "cannot find the container.NewContainer call; pass it the config by hand")
+	}
+
+	off := cs.offset(call.Lparen) + 1
+	text := cfg
+	if len(call.Args) > 0 {
+		text += ", "
+	}
+	if err := cs.apply(edit{off, off, text}); err != nil {
+		return err
+	}
+
+	return writeFile(mainPath, cs.src)
+}
+
+// nextMigration returns the path, without the .up.sql/.down.sql suffix, of
+// a new migration numbered after the ones in migrations/.
+func nextMigration(name string) (string, error) {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	last := 0
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		if n, err := strconv.Atoi(prefix); err == nil && n > last {
+			last = n
+		}
+	}
+
+	return filepath.Join(migrationsDir, fmt.Sprintf("%06d_%s", last+1, name)), nil
+}
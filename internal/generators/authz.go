@@ -0,0 +1,244 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var (
+	permissionsPath         = filepath.Join("internal", "domain", "security", "permissions.go")
+	authorizeMiddlewarePath = filepath.Join("internal", "infrastructure", "http", "middleware", "authorize.go")
+)
+
+// ModuleActions are the use cases of a generated module, which --authz
+// grants to roles.
+var ModuleActions = []string{"create", "get", "list", "update", "delete"}
+
+// anyRole is security.AnyRole in the generated project.
+const anyRole = "any"
+
+// ParseAuthz parses an --authz policy such as "create:admin,list:any" into
+// the roles granted each action. Several roles are separated by "|", as in
+// "update:admin|editor". Actions left out are granted to no role, so they
+// are denied until someone edits the registry.
+func ParseAuthz(policy string) (map[string][]string, error) {
+	authz := make(map[string][]string)
+
+	for _, rule := range strings.Split(policy, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		action, roles, ok := strings.Cut(rule, ":")
+		action = strings.TrimSpace(action)
+		if !ok || roles == "" {
+			return nil, fmt.Errorf("invalid --authz rule '%s' (expected action:role)", rule)
+		}
+		if !slices.Contains(ModuleActions, action) {
+			return nil, fmt.Errorf("unknown action '%s' in --authz (expected %s)", action, strings.Join(ModuleActions, ", "))
+		}
+		if _, ok := authz[action]; ok {
+			return nil, fmt.Errorf("action '%s' appears twice in --authz", action)
+		}
+
+		for _, role := range strings.Split(roles, "|") {
+			role = strings.TrimSpace(role)
+			if role == "" {
+				return nil, fmt.Errorf("empty role in --authz rule '%s'", rule)
+			}
+			authz[action] = append(authz[action], role)
+		}
+	}
+
+	if len(authz) == 0 {
+		return nil, fmt.Errorf("--authz needs at least one action:role rule")
+	}
+
+	for _, action := range ModuleActions {
+		if _, ok := authz[action]; !ok {
+			authz[action] = []string{}
+		}
+	}
+
+	return authz, nil
+}
+
+// setupAuthz writes the permission registry and the Authorize middleware,
+// for projects that ran `gozilla add auth` before they were part of it.
+func setupAuthz() error {
+	project, err := projectData()
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		permissionsPath:         projecttemplates.PermissionsTemplate(project),
+		authorizeMiddlewarePath: projecttemplates.AuthorizeMiddlewareTemplate(project),
+	}
+
+	for path, content := range files {
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if formatted, err := format.Source([]byte(content)); err == nil {
+			content = string(formatted)
+		}
+		if err := writeFile(path, []byte(content)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// registerPermissions adds the permissions of a module generated with
+// --authz to security.Permissions. Permissions already there keep their
+// roles.
+func registerPermissions(data templates.ModuleData) error {
+	src, err := os.ReadFile(permissionsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read permissions.go: %w", err)
+	}
+
+	cs, err := parseSource(permissionsPath, src)
+	if err != nil {
+		return err
+	}
+
+	registry, err := cs.permissionRegistry()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, elt := range registry.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, err := strconv.Unquote(cs.text(kv.Key)); err == nil {
+				existing[key] = true
+			}
+		}
+	}
+
+	var lines []string
+	for _, action := range ModuleActions {
+		permission := data.Permission(action)
+		if existing[permission] {
+			continue
+		}
+
+		var roles []string
+		for _, role := range data.Authz[action] {
+			if role == anyRole {
+				roles = append(roles, "AnyRole")
+			} else {
+				roles = append(roles, strconv.Quote(role))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("\t%q: {%s},", permission, strings.Join(roles, ", ")))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	text := strings.Join(lines, "\n")
+
+	switch {
+	case len(registry.Elts) > 0:
+		err = cs.insertAfter(registry.Elts[len(registry.Elts)-1], registry.Rbrace, text)
+	case cs.line(registry.Lbrace) == cs.line(registry.Rbrace):
+		off := cs.offset(registry.Rbrace)
+		err = cs.apply(edit{off, off, "\n" + text + "\n"})
+	default:
+		off := cs.lineStart(registry.Rbrace)
+		err = cs.apply(edit{off, off, text + "\n"})
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeFile(permissionsPath, cs.src)
+}
+
+// permissionRegistry returns the map literal of `var Permissions = ...`.
+func (cs *containerSource) permissionRegistry() (*ast.CompositeLit, error) {
+	for _, decl := range cs.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, name := range value.Names {
+				if name.Name != "Permissions" || i >= len(value.Values) {
+					continue
+				}
+				if lit, ok := value.Values[i].(*ast.CompositeLit); ok {
+					return lit, nil
+				}
+				return nil, cs.errorf(value, "Permissions is not a map literal; add the permissions by hand")
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s: cannot find var Permissions", cs.path)
+}
+
+// readPermissions returns the roles granted each permission in
+// security.Permissions, with security.AnyRole as "any".
+func readPermissions() (map[string][]string, error) {
+	src, err := os.ReadFile(permissionsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := parseSource(permissionsPath, src)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := cs.permissionRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string][]string)
+	for _, elt := range registry.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, err := strconv.Unquote(cs.text(kv.Key))
+		if err != nil {
+			continue
+		}
+
+		roles := []string{}
+		if lit, ok := kv.Value.(*ast.CompositeLit); ok {
+			for _, role := range lit.Elts {
+				if value, err := strconv.Unquote(cs.text(role)); err == nil {
+					roles = append(roles, value)
+				} else if strings.HasSuffix(cs.text(role), "AnyRole") {
+					roles = append(roles, anyRole)
+				} else {
+					roles = append(roles, cs.text(role))
+				}
+			}
+		}
+		permissions[key] = roles
+	}
+
+	return permissions, nil
+}
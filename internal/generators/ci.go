@@ -0,0 +1,88 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+// CIProviders are the CI services `--ci` and `add ci` generate pipelines for.
+var CIProviders = []string{"github", "gitlab"}
+
+// ciFiles returns the pipeline files of a CI provider, by path from the
+// project root.
+func ciFiles(data projecttemplates.ProjectData) (map[string]string, error) {
+	switch data.CI {
+	case "github":
+		return map[string]string{
+			filepath.Join(".github", "workflows", "ci.yml"): projecttemplates.GitHubWorkflowTemplate(data),
+		}, nil
+	case "gitlab":
+		return map[string]string{
+			".gitlab-ci.yml": projecttemplates.GitLabCITemplate(data),
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown CI '%s' (expected %s)", data.CI, strings.Join(CIProviders, " or "))
+}
+
+type CIGenerator struct{}
+
+func NewCIGenerator() *CIGenerator {
+	return &CIGenerator{}
+}
+
+// Generate writes the pipeline of the given CI provider to the project in
+// the working directory, along with the migrate-check Makefile target it
+// runs.
+func (g *CIGenerator) Generate(provider string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+	data := projecttemplates.ProjectData{
+		ProjectName: modulePath,
+		ModulePath:  modulePath,
+		ProjectDir:  dir,
+		CI:          provider,
+		GoVersion:   project.GoVersion("go.mod"),
+	}
+
+	files, err := ciFiles(data)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for path := range files {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(path, []byte(files[path])); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if err := appendIfMissing("Makefile", "\nmigrate-check:", projecttemplates.MigrateCheckMakeTarget()); err != nil {
+		return fmt.Errorf("failed to update Makefile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,451 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+// configTypes are the setting types of `generate config`: the type of the
+// field and the getter Load reads it with.
+var configTypes = map[string]struct{ fieldType, getter string }{
+	"string":   {"string", "getEnv"},
+	"int":      {"int", "getIntEnv"},
+	"int64":    {"int64", "getInt64Env"},
+	"bool":     {"bool", "getBoolEnv"},
+	"duration": {"time.Duration", "getDurationEnv"},
+	"list":     {"[]string", "getListEnv"},
+}
+
+// ConfigTypes lists the setting types `generate config` accepts.
+func ConfigTypes() []string {
+	names := make([]string, 0, len(configTypes))
+	for name := range configTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var configKeyPart = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ConfigOptions are the flags of `generate config`.
+type ConfigOptions struct {
+	Default  string
+	Required bool
+}
+
+// ConfigSetting is a setting added by `generate config`.
+type ConfigSetting struct {
+	Field string // Go field, such as Orders.MaxItems
+	Env   string // environment variable, such as ORDERS_MAX_ITEMS
+}
+
+type ConfigGenerator struct{}
+
+func NewConfigGenerator() *ConfigGenerator {
+	return &ConfigGenerator{}
+}
+
+// Generate adds a setting to config.Config, read in Load, and to
+// .env.example. The key is a name such as max_items or, to put the setting
+// in the section of a module, orders.max_items; the section is a struct of
+// its own, OrdersConfig, which the module can take in New<X>Module.
+func (g *ConfigGenerator) Generate(key, kind string, opts ConfigOptions) (ConfigSetting, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(key)), ".")
+	if len(parts) > 2 {
+		return ConfigSetting{}, fmt.Errorf("invalid key '%s': use <name> or <section>.<name>", key)
+	}
+	for _, part := range parts {
+		if !configKeyPart.MatchString(part) {
+			return ConfigSetting{}, fmt.Errorf("invalid key '%s': use lowercase words joined by '_', such as max_items", key)
+		}
+	}
+
+	if opts.Required && strings.TrimSpace(opts.Default) != "" {
+		return ConfigSetting{}, fmt.Errorf("--required and --default exclude each other: Load fails without a required setting, so its default is never used")
+	}
+
+	typ, ok := configTypes[kind]
+	if !ok {
+		return ConfigSetting{}, fmt.Errorf("unknown type '%s' (expected %s)", kind, strings.Join(ConfigTypes(), ", "))
+	}
+	value, err := configDefault(kind, opts.Default)
+	if err != nil {
+		return ConfigSetting{}, err
+	}
+
+	env := strings.ToUpper(strings.ReplaceAll(strings.Join(parts, "_"), "-", "_"))
+	field := configField(parts[len(parts)-1])
+	setting := ConfigSetting{Field: field, Env: env}
+
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		return setting, fmt.Errorf("failed to read config.go: %w", err)
+	}
+	cs, err := parseSource(configPath, src)
+	if err != nil {
+		return setting, err
+	}
+
+	if configReads(cs, env) {
+		return setting, fmt.Errorf("%s is already read in %s", env, configPath)
+	}
+
+	envKey := strconv.Quote(env)
+	if opts.Required {
+		if !cs.declaresConfig("required") {
+			return setting, fmt.Errorf("%s has no required(); --required needs the validating config of newer projects", configPath)
+		}
+		envKey = "required(" + envKey + ")"
+	}
+	read := fmt.Sprintf("%s(%s, %s)", typ.getter, envKey, value)
+
+	if len(parts) == 1 {
+		err = cs.addConfigGroup("Application settings", configSetting{field, typ.fieldType, read})
+	} else {
+		section := configField(parts[0])
+		setting.Field = section + "." + field
+		err = cs.addConfigSection(section, parts[0], configSetting{field, typ.fieldType, read})
+	}
+	if err != nil {
+		return setting, err
+	}
+
+	// Projects generated before typed settings may lack the getter
+	if !cs.declaresConfig(typ.getter) {
+		helper, imports := projecttemplates.ConfigHelper(typ.getter)
+		if err := cs.apply(edit{len(cs.src), len(cs.src), helper}); err != nil {
+			return setting, err
+		}
+		for _, p := range imports {
+			if err := cs.addImport(p); err != nil {
+				return setting, err
+			}
+		}
+	}
+	if kind == "duration" {
+		if err := cs.addImport("time"); err != nil {
+			return setting, err
+		}
+	}
+
+	if err := writeFile(configPath, cs.src); err != nil {
+		return setting, err
+	}
+
+	line := env + "=" + strings.TrimSpace(opts.Default) + "\n"
+	if err := appendIfMissing(".env.example", "\n"+env+"=", line); err != nil {
+		return setting, fmt.Errorf("failed to update .env.example: %w", err)
+	}
+
+	return setting, nil
+}
+
+// addConfigSection adds setting to the section struct of config.Config,
+// such as OrdersConfig, creating the section when it is the first.
+func (cs *containerSource) addConfigSection(section, key string, setting configSetting) error {
+	structName := section + "Config"
+	if cs.structType(structName) != nil {
+		return cs.addConfigSetting(structName, "", setting)
+	}
+
+	decl := fmt.Sprintf("\n// %s holds the %s settings: the %s_* environment\n// variables, or the %s: section of the config files.\ntype %s struct {\n\t%s %s\n}\n",
+		structName, key, strings.ToUpper(key), key, structName, setting.field, setting.fieldType)
+	if err := cs.apply(edit{len(cs.src), len(cs.src), decl}); err != nil {
+		return err
+	}
+
+	literal := fmt.Sprintf("%s{\n%s: %s,\n}", structName, setting.field, setting.value)
+	return cs.addConfigSetting("Config", "", configSetting{section, structName, literal})
+}
+
+// configInitialisms are the words config fields spell in capitals.
+var configInitialisms = map[string]bool{
+	"api": true, "dsn": true, "http": true, "id": true, "ip": true, "jwt": true,
+	"sql": true, "tls": true, "ttl": true, "uri": true, "url": true,
+}
+
+// configField returns the Go field name of a key: max_items is MaxItems
+// and cache_ttl is CacheTTL.
+func configField(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' })
+	for i, word := range words {
+		if configInitialisms[word] {
+			words[i] = strings.ToUpper(word)
+		}
+	}
+	return pascalCase(strings.Join(words, "_"))
+}
+
+// lastConfigSetting returns the last field of config.Config that is not a
+// section, below which new settings go.
+func lastConfigSetting(cs *containerSource) string {
+	structType := cs.structType("Config")
+	if structType == nil {
+		return ""
+	}
+
+	last := ""
+	for _, field := range structType.Fields.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && cs.structType(ident.Name) != nil {
+			continue
+		}
+		for _, name := range field.Names {
+			last = name.Name
+		}
+	}
+	return last
+}
+
+// addConfigGroup adds settings to config.Config in a group of their own,
+// under comment, after the last field that is not a section; in Load, they
+// get a group of their own too. Settings of a group that is already there
+// go at its end, and settings already declared are left alone.
+func (cs *containerSource) addConfigGroup(comment string, settings ...configSetting) error {
+	structType := cs.structType("Config")
+	if structType == nil {
+		return fmt.Errorf("%s: cannot find the Config struct; add the %s settings by hand", configPath, comment)
+	}
+
+	declared := make(map[string]bool)
+	var last *ast.Field
+	group, inGroup := "", false
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			declared[name.Name] = true
+		}
+		if ident, ok := field.Type.(*ast.Ident); ok && cs.structType(ident.Name) != nil {
+			continue
+		}
+
+		// A group starts at a doc comment or a blank line.
+		switch {
+		case field.Doc != nil:
+			inGroup = strings.TrimSpace(field.Doc.Text()) == comment
+		case last != nil && cs.line(field.Pos()) > cs.line(last.End())+1:
+			inGroup = false
+		}
+		if inGroup && len(field.Names) > 0 {
+			group = field.Names[len(field.Names)-1].Name
+		}
+		last = field
+	}
+
+	var pending []configSetting
+	for _, setting := range settings {
+		if !declared[setting.field] {
+			pending = append(pending, setting)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if group != "" || last == nil {
+		after := group
+		for _, setting := range pending {
+			if err := cs.addConfigSetting("Config", after, setting); err != nil {
+				return err
+			}
+			after = setting.field
+		}
+		return nil
+	}
+
+	lastField := last.Names[len(last.Names)-1].Name
+	fields := "\n\t// " + comment
+	for _, setting := range pending {
+		fields += "\n\t" + setting.field + " " + setting.fieldType
+	}
+	if err := cs.insertAfter(last, structType.Fields.Closing, fields); err != nil {
+		return err
+	}
+
+	lit := cs.configLiteral("Config")
+	if lit == nil || !cs.declaresConfig("getEnv") {
+		return fmt.Errorf("%s: cannot find where Load builds the Config; set the %s settings by hand", configPath, comment)
+	}
+	var anchor ast.Expr
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && (key.Name == lastField || anchor == nil) {
+				anchor = elt
+			}
+		}
+	}
+
+	var entries string
+	for _, setting := range pending {
+		entries += "\n\t" + setting.field + ": " + cs.configRead(setting.value) + ","
+	}
+	if anchor == nil {
+		off := cs.offset(lit.Rbrace)
+		return cs.apply(edit{off, off, entries + "\n"})
+	}
+	return cs.insertAfter(anchor, lit.Rbrace, entries)
+}
+
+// configDeclares reports whether config.go declares the getter name.
+func configDeclares(name string) bool {
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		return false
+	}
+	cs, err := parseSource(configPath, src)
+	return err == nil && cs.declaresConfig(name)
+}
+
+// declaresConfig reports whether config.go declares the getter name, as a
+// function in older configs or as a method of the settings Load reads.
+func (cs *containerSource) declaresConfig(name string) bool {
+	return cs.function(name) != nil || cs.method(name) != nil
+}
+
+// configCall matches the calls of the getters and of required.
+var configCall = regexp.MustCompile(`\b(getEnv|getIntEnv|getInt64Env|getBoolEnv|getDurationEnv|getListEnv|required)\(`)
+
+// configRead adapts a read written with the getters of older configs, such
+// as getEnv("KEY", ""), to configs whose Load reads through a settings
+// value: s.getEnv("KEY", "").
+func (cs *containerSource) configRead(value string) string {
+	recv := cs.configReceiver()
+	if recv == "" {
+		return value
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range configCall.FindAllStringIndex(value, -1) {
+		if m[0] > 0 && value[m[0]-1] == '.' {
+			continue
+		}
+		b.WriteString(value[last:m[0]])
+		b.WriteString(recv + ".")
+		last = m[0]
+	}
+	b.WriteString(value[last:])
+	return b.String()
+}
+
+// configReceiver returns the variable Load calls the getters on, or "" when
+// they are functions.
+func (cs *containerSource) configReceiver() string {
+	fn := cs.function("Load")
+	if fn == nil {
+		return ""
+	}
+
+	recv := ""
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && recv == "" {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && configGetters[sel.Sel.Name] {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					recv = ident.Name
+				}
+			}
+		}
+		return recv == ""
+	})
+	return recv
+}
+
+// configReads reports whether config.go already reads the environment
+// variable env.
+func configReads(cs *containerSource, env string) bool {
+	found := false
+	ast.Inspect(cs.file, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if value, _ := strconv.Unquote(lit.Value); value == env {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// configDefault returns the Go expression of the default value of a
+// setting of the given kind, or its zero value when none is given.
+func configDefault(kind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	invalid := fmt.Errorf("invalid default '%s' for a %s setting", value, kind)
+
+	switch kind {
+	case "string":
+		return strconv.Quote(value), nil
+	case "int", "int64":
+		if value == "" {
+			return "0", nil
+		}
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "", invalid
+		}
+		return value, nil
+	case "bool":
+		if value == "" {
+			return "false", nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatBool(b), nil
+	case "duration":
+		if value == "" {
+			return "0", nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return "", invalid
+		}
+		return durationLiteral(d), nil
+	case "list":
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, strconv.Quote(item))
+			}
+		}
+		if len(items) == 0 {
+			return "nil", nil
+		}
+		return "[]string{" + strings.Join(items, ", ") + "}", nil
+	}
+	return "", invalid
+}
+
+// durationLiteral writes d in the largest unit that divides it, such as
+// 90*time.Second or time.Hour.
+func durationLiteral(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			if d == u.unit {
+				return u.name
+			}
+			return fmt.Sprintf("%d*%s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("%d", int64(d))
+}
@@ -0,0 +1,98 @@
+package generators
+
+import "testing"
+
+const testConfig = `package config
+
+type Config struct {
+	Port string
+
+	// HTTP server
+	ReadTimeout time.Duration
+
+	Orders OrdersConfig
+}
+
+type OrdersConfig struct {
+	Limit int
+}
+
+func Load() (*Config, error) {
+	s := &settings{}
+	cfg := &Config{
+		Port: s.getEnv("PORT", "8080"),
+
+		ReadTimeout: s.getDurationEnv("HTTP_READ_TIMEOUT", 15*time.Second),
+		Orders: OrdersConfig{
+			Limit: s.getIntEnv("ORDERS_LIMIT", 5),
+		},
+	}
+	return cfg, nil
+}
+
+func (s *settings) getEnv(key, fallback string) string { return fallback }
+`
+
+func TestAddConfigGroup(t *testing.T) {
+	cs, err := parseSource("config.go", []byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cs.addConfigGroup("Authentication",
+		configSetting{"JWTSecret", "string", `getEnv("JWT_SECRET", "")`},
+		configSetting{"JWTAccessTTL", "time.Duration", `getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute)`},
+	); err != nil {
+		t.Fatal(err)
+	}
+	// A second setting of the group joins it; one already there is skipped.
+	if err := cs.addConfigGroup("Authentication",
+		configSetting{"JWTSecret", "string", `getEnv("JWT_SECRET", "")`},
+		configSetting{"JWTRefreshTTL", "time.Duration", `getDurationEnv("JWT_REFRESH_TTL", time.Hour)`},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `package config
+
+type Config struct {
+	Port string
+
+	// HTTP server
+	ReadTimeout time.Duration
+
+	// Authentication
+	JWTSecret     string
+	JWTAccessTTL  time.Duration
+	JWTRefreshTTL time.Duration
+
+	Orders OrdersConfig
+}
+
+type OrdersConfig struct {
+	Limit int
+}
+
+func Load() (*Config, error) {
+	s := &settings{}
+	cfg := &Config{
+		Port: s.getEnv("PORT", "8080"),
+
+		ReadTimeout: s.getDurationEnv("HTTP_READ_TIMEOUT", 15*time.Second),
+
+		JWTSecret:     s.getEnv("JWT_SECRET", ""),
+		JWTAccessTTL:  s.getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
+		JWTRefreshTTL: s.getDurationEnv("JWT_REFRESH_TTL", time.Hour),
+		Orders: OrdersConfig{
+			Limit: s.getIntEnv("ORDERS_LIMIT", 5),
+		},
+	}
+	return cfg, nil
+}
+
+func (s *settings) getEnv(key, fallback string) string { return fallback }
+`
+	if got := string(cs.src); got != want {
+		t.Errorf("config.go after addConfigGroup():\n%s\nwant:\n%s", got, want)
+	}
+}
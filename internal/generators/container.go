@@ -1,18 +1,24 @@
 package generators
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
 )
 
+var containerPath = filepath.Join("internal", "infrastructure", "container", "container.go")
+
 type ContainerUpdater struct{}
 
 func NewContainerUpdater() *ContainerUpdater {
@@ -20,232 +26,1192 @@ func NewContainerUpdater() *ContainerUpdater {
 }
 
 func (u *ContainerUpdater) AddModule(moduleName string) error {
-	containerPath := filepath.Join("internal", "infrastructure", "container", "container.go")
-
 	// Read the file
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, containerPath, nil, parser.ParseComments)
+	src, err := os.ReadFile(containerPath)
+	if err != nil {
+		return fmt.Errorf("failed to read container.go: %w", err)
+	}
+
+	cs, err := parseContainer(src)
 	if err != nil {
-		return fmt.Errorf("failed to parse container.go: %w", err)
+		return err
 	}
 
+	if err := u.wireModule(cs, moduleName, nil); err != nil {
+		return err
+	}
+
+	// Write back to file
+	return writeFile(containerPath, cs.src)
+}
+
+// wireModule adds whatever wiring of the module container.go is missing.
+// fallback holds the constructor arguments the module was last built with.
+func (u *ContainerUpdater) wireModule(cs *containerSource, moduleName string, fallback []string) error {
 	moduleNameTitle := strings.Title(moduleName)
 	moduleVarName := moduleNameTitle + "Module"
-	moduleImportPath := fmt.Sprintf("%s/internal/modules/%s", templates.GetModulePath(), moduleName)
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+	moduleImportPath := modulesImportPath(modulePath) + "/" + moduleName
 
 	// Add import
-	u.addImport(file, moduleName, moduleImportPath)
+	if err := cs.addImport(moduleImportPath); err != nil {
+		return err
+	}
 
 	// Update Container struct
-	u.addFieldToStruct(file, "Container", moduleVarName, "*"+moduleName+"."+moduleNameTitle+"Module")
+	if err := cs.addField(moduleVarName, "*"+moduleName+"."+moduleVarName); err != nil {
+		return err
+	}
 
 	// Update NewContainer function
-	u.addModuleToConstructor(file, moduleVarName, moduleName, moduleNameTitle)
+	if err := cs.provideLogger(moduleName); err != nil {
+		return err
+	}
+	if err := cs.addConstruction(moduleName, moduleVarName, fallback); err != nil {
+		return err
+	}
 
-	// Update RegisterRoutes method
-	u.addRouteRegistration(file, moduleVarName)
+	// Register the module on each transport it serves
+	methods := moduleMethods(moduleName)
+	if methods["RegisterRoutes"] {
+		if err := cs.addRouteRegistration(moduleVarName); err != nil {
+			return err
+		}
+	}
+	for _, r := range registrars() {
+		if !methods[r.method] {
+			continue
+		}
+		if err := cs.addRegistration(r, moduleVarName); err != nil {
+			return err
+		}
+	}
 
-	// Write back to file
-	f, err := os.Create(containerPath)
+	// Stop it on shutdown, before the modules it depends on
+	return cs.addCloser(moduleVarName)
+}
+
+// containerSource is container.go while it is being edited. Edits are made
+// on the source text at positions taken from the AST, then the file is run
+// through gofmt and parsed again, so comments and hand-made layout survive.
+// main.go and config.go are edited the same way when a module needs them.
+type containerSource struct {
+	path string
+	src  []byte
+	fset *token.FileSet
+	file *ast.File
+}
+
+func parseContainer(src []byte) (*containerSource, error) {
+	return parseSource(containerPath, src)
+}
+
+func parseSource(path string, src []byte) (*containerSource, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
 	}
-	defer f.Close()
 
-	if err := format.Node(f, fset, file); err != nil {
-		return fmt.Errorf("failed to format file: %w", err)
+	return &containerSource{path: path, src: src, fset: fset, file: file}, nil
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// apply replaces the given byte ranges, formats the result and re-parses it.
+func (cs *containerSource) apply(edits ...edit) error {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+
+	src := append([]byte(nil), cs.src...)
+	for _, e := range edits {
+		src = append(src[:e.start], append([]byte(e.text), src[e.end:]...)...)
 	}
 
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("failed to format %s after update: %w", filepath.Base(cs.path), err)
+	}
+
+	updated, err := parseSource(cs.path, formatted)
+	if err != nil {
+		return err
+	}
+
+	*cs = *updated
 	return nil
 }
 
-func (u *ContainerUpdater) addImport(file *ast.File, alias, path string) {
-	// Check if import already exists
-	for _, imp := range file.Imports {
-		if imp.Path.Value == `"`+path+`"` {
-			return
-		}
+func (cs *containerSource) offset(p token.Pos) int {
+	return cs.fset.Position(p).Offset
+}
+
+func (cs *containerSource) line(p token.Pos) int {
+	return cs.fset.Position(p).Line
+}
+
+// lineStart returns the offset of the first byte of the line holding p.
+func (cs *containerSource) lineStart(p token.Pos) int {
+	off := cs.offset(p)
+	return bytes.LastIndexByte(cs.src[:off], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline of the line holding p.
+func (cs *containerSource) lineEnd(p token.Pos) int {
+	off := cs.offset(p)
+	if i := bytes.IndexByte(cs.src[off:], '\n'); i >= 0 {
+		return off + i + 1
 	}
+	return len(cs.src)
+}
+
+func (cs *containerSource) text(node ast.Node) string {
+	return string(cs.src[cs.offset(node.Pos()):cs.offset(node.End())])
+}
 
-	// Add new import
-	newImport := &ast.ImportSpec{
-		Path: &ast.BasicLit{
-			Kind:  token.STRING,
-			Value: `"` + path + `"`,
-		},
+// errorf reports a problem at the position of node.
+func (cs *containerSource) errorf(node ast.Node, format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", cs.path, cs.line(node.Pos()), fmt.Sprintf(format, args...))
+}
+
+// insertAfter adds line as a new line below anchor. When the closing token of
+// the enclosing block sits on anchor's own line, the line goes before it.
+func (cs *containerSource) insertAfter(anchor ast.Node, closing token.Pos, line string) error {
+	if cs.line(anchor.End()) == cs.line(closing) {
+		off := cs.offset(closing)
+		return cs.apply(edit{off, off, "\n" + line + "\n"})
 	}
 
-	// Find or create import declaration
-	var importDecl *ast.GenDecl
-	for _, decl := range file.Decls {
-		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
-			importDecl = genDecl
-			break
+	off := cs.lineEnd(anchor.End())
+	return cs.apply(edit{off, off, line + "\n"})
+}
+
+// removal returns the edit deleting node (and its doc comment). Whole lines
+// are removed when nothing else shares them; otherwise only the node and the
+// comma following it are.
+func (cs *containerSource) removal(node ast.Node, doc *ast.CommentGroup) edit {
+	start, end := cs.offset(node.Pos()), cs.offset(node.End())
+
+	after := end
+	for after < len(cs.src) && (cs.src[after] == ' ' || cs.src[after] == '\t') {
+		after++
+	}
+	if after < len(cs.src) && cs.src[after] == ',' {
+		end = after + 1
+	}
+
+	lineStart := cs.lineStart(node.Pos())
+	lineEnd := cs.lineEnd(node.End())
+	before := strings.TrimSpace(string(cs.src[lineStart:start]))
+	rest := strings.TrimSpace(string(cs.src[end:lineEnd]))
+	if before == "" && (rest == "" || strings.HasPrefix(rest, "//")) {
+		if doc != nil {
+			lineStart = cs.lineStart(doc.Pos())
 		}
+		return edit{lineStart, lineEnd, ""}
 	}
 
-	if importDecl == nil {
-		// Create new import declaration
-		importDecl = &ast.GenDecl{
-			Tok:   token.IMPORT,
-			Specs: []ast.Spec{},
+	for end < len(cs.src) && cs.src[end] == ' ' {
+		end++
+	}
+	return edit{start, end, ""}
+}
+
+func (cs *containerSource) importDecl() *ast.GenDecl {
+	for _, decl := range cs.file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			return genDecl
 		}
-		file.Decls = append([]ast.Decl{importDecl}, file.Decls...)
 	}
+	return nil
+}
 
-	importDecl.Specs = append(importDecl.Specs, newImport)
+func (cs *containerSource) containerStruct() *ast.StructType {
+	return cs.structType("Container")
 }
 
-func (u *ContainerUpdater) addFieldToStruct(file *ast.File, structName, fieldName, fieldType string) {
-	for _, decl := range file.Decls {
+func (cs *containerSource) structType(name string) *ast.StructType {
+	for _, decl := range cs.file.Decls {
 		genDecl, ok := decl.(*ast.GenDecl)
 		if !ok || genDecl.Tok != token.TYPE {
 			continue
 		}
-
 		for _, spec := range genDecl.Specs {
 			typeSpec, ok := spec.(*ast.TypeSpec)
-			if !ok || typeSpec.Name.Name != structName {
+			if !ok || typeSpec.Name.Name != name {
 				continue
 			}
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				return structType
+			}
+		}
+	}
+	return nil
+}
 
-			structType, ok := typeSpec.Type.(*ast.StructType)
-			if !ok {
-				continue
+func (cs *containerSource) function(name string) *ast.FuncDecl {
+	for _, decl := range cs.file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil && funcDecl.Name.Name == name {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+func (cs *containerSource) method(name string) *ast.FuncDecl {
+	for _, decl := range cs.file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv != nil && funcDecl.Name.Name == name {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+func (cs *containerSource) addImport(importPath string) error {
+	return cs.addNamedImport("", importPath)
+}
+
+// addNamedImport imports importPath under the given package name, or its
+// own name when name is empty.
+func (cs *containerSource) addNamedImport(name, importPath string) error {
+	// Check if import already exists
+	for _, imp := range cs.file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+			return nil
+		}
+	}
+
+	quoted := strconv.Quote(importPath)
+	if name != "" {
+		quoted = name + " " + quoted
+	}
+
+	decl := cs.importDecl()
+	if decl == nil {
+		off := cs.offset(cs.file.Name.End())
+		return cs.apply(edit{off, off, "\n\nimport " + quoted + "\n"})
+	}
+
+	if !decl.Lparen.IsValid() {
+		spec := decl.Specs[0]
+		start, end := cs.offset(spec.Pos()), cs.offset(spec.End())
+		return cs.apply(edit{start, end, "(\n\t" + cs.text(spec) + "\n\t" + quoted + "\n)"})
+	}
+
+	if len(decl.Specs) == 0 {
+		off := cs.offset(decl.Rparen)
+		return cs.apply(edit{off, off, "\n\t" + quoted + "\n"})
+	}
+
+	// Join the import group whose paths look most like the new one, so the
+	// project's own imports stay apart from the standard library and others.
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+	var anchor ast.Spec
+	best := -1
+	for _, spec := range decl.Specs {
+		p, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value)
+		n := commonPrefixLen(p, importPath)
+		if isStandardLibrary(p, modulePath) && isStandardLibrary(importPath, modulePath) {
+			n = len(importPath)
+		}
+		if n >= best {
+			anchor, best = spec, n
+		}
+	}
+
+	return cs.insertAfter(anchor, decl.Rparen, "\t"+quoted)
+}
+
+func (cs *containerSource) addField(fieldName, fieldType string) error {
+	structType := cs.containerStruct()
+	if structType == nil {
+		return fmt.Errorf("%s: cannot find the Container struct; declare `type Container struct { ... }`", containerPath)
+	}
+
+	// Check if field already exists
+	var anchor *ast.Field
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == fieldName {
+				return nil
 			}
+		}
+		if _, ok := modulePackage(field.Type); ok || anchor == nil {
+			anchor = field
+		} else if _, ok := modulePackage(anchor.Type); !ok {
+			anchor = field
+		}
+	}
+
+	line := "\t" + fieldName + " " + fieldType
+	if anchor == nil {
+		off := cs.offset(structType.Fields.Closing)
+		return cs.apply(edit{off, off, "\n" + line + "\n"})
+	}
+
+	// New modules go below the last module field, keeping any comments and
+	// non-module fields where they were.
+	return cs.insertAfter(anchor, structType.Fields.Closing, line)
+}
+
+// How NewContainer builds modules.
+const (
+	// buildOrdered: `usersModule := users.NewUsersModule(db)` statements in
+	// dependency order, then `UsersModule: usersModule` in the literal.
+	buildOrdered = iota
+	// buildAssigned: `c.UsersModule = users.NewUsersModule(db)` statements.
+	buildAssigned
+	// buildInline: `UsersModule: users.NewUsersModule(db)` in the literal.
+	buildInline
+)
+
+// containerBuilder is the code that builds the Container: NewContainer or
+// a helper function it calls.
+type containerBuilder struct {
+	fn    *ast.FuncDecl
+	lit   *ast.CompositeLit
+	style int
+	recv  string            // Container variable, for buildAssigned
+	vars  map[string]string // module package -> variable, for buildOrdered
+}
+
+func (cs *containerSource) builder() (*containerBuilder, error) {
+	constructor := cs.function("NewContainer")
+	if constructor == nil {
+		return nil, fmt.Errorf("%s: cannot find func NewContainer", containerPath)
+	}
+
+	lit, fn := cs.containerLiteral(constructor, make(map[string]bool))
+	if fn == nil {
+		fn = constructor
+	}
+
+	b := &containerBuilder{fn: fn, lit: lit, vars: make(map[string]string)}
+	for _, stmt := range fn.Body.List {
+		if pkg, name, ok := moduleDefinition(stmt); ok {
+			b.vars[pkg] = name
+		}
+	}
+
+	assign := cs.lastModuleAssignment(fn)
+	if assign == nil {
+		assign = cs.lastModuleAssignment(constructor)
+	}
+
+	switch {
+	case len(b.vars) > 0:
+		b.style = buildOrdered
+	case assign != nil:
+		b.style = buildAssigned
+		b.recv = cs.text(assign.Lhs[0].(*ast.SelectorExpr).X)
+	case lit != nil && cs.hasInlineModules(lit):
+		b.style = buildInline
+	case lit != nil:
+		b.style = buildOrdered
+	default:
+		return nil, cs.errorf(constructor, "cannot find where NewContainer builds the Container "+
+			"(looked for a Container{...} literal and c.XModule = ... assignments)")
+	}
+
+	return b, nil
+}
+
+// moduleRef returns the expression NewContainer uses to pass an already
+// built module to another module's constructor.
+func (b *containerBuilder) moduleRef(moduleName string) (string, error) {
+	switch b.style {
+	case buildOrdered:
+		if name, ok := b.vars[moduleName]; ok {
+			return name, nil
+		}
+		return moduleName + "Module", nil
+	case buildAssigned:
+		return b.recv + "." + strings.Title(moduleName) + "Module", nil
+	default:
+		return "", fmt.Errorf("NewContainer builds modules inside the Container literal, so '%s' cannot be passed to "+
+			"another module; run `gozilla sync container` to switch to ordered construction", moduleName)
+	}
+}
+
+// addConstruction makes NewContainer build the module, following the style
+// (see buildOrdered and friends) the constructor already uses.
+func (cs *containerSource) addConstruction(moduleName, fieldName string, fallback []string) error {
+	b, err := cs.builder()
+	if err != nil {
+		return fmt.Errorf("%w; add %s by hand", err, fieldName)
+	}
+
+	args, err := constructorArgs(moduleName, fallback, b.moduleRef)
+	if err != nil {
+		return err
+	}
+	call := fmt.Sprintf("%s.New%s(%s)", moduleName, fieldName, strings.Join(args, ", "))
+
+	switch b.style {
+	case buildAssigned:
+		if cs.constructs(b.fn, moduleName) || cs.constructs(cs.function("NewContainer"), moduleName) {
+			return nil
+		}
+		assign := cs.lastModuleAssignment(b.fn)
+		if assign == nil {
+			assign = cs.lastModuleAssignment(cs.function("NewContainer"))
+		}
+		line := fmt.Sprintf("\t%s.%s = %s", b.recv, fieldName, call)
+		return cs.insertAfter(assign, cs.enclosingBlock(assign), line)
+
+	case buildInline:
+		if cs.constructs(b.fn, moduleName) {
+			return nil
+		}
+		return cs.addLiteralEntry(fieldName, call)
+	}
+
+	varName, defined := b.vars[moduleName]
+	if !defined {
+		varName = moduleName + "Module"
+		line := fmt.Sprintf("\t%s := %s", varName, call)
+
+		var last ast.Stmt
+		for _, stmt := range b.fn.Body.List {
+			if _, _, ok := moduleDefinition(stmt); ok {
+				last = stmt
+			}
+		}
+
+		if last != nil {
+			// After every module built so far, so its dependencies exist.
+			err = cs.insertAfter(last, b.fn.Body.Rbrace, line)
+		} else {
+			stmt := cs.statementHolding(b.fn, b.lit)
+			off := cs.lineStart(stmt.Pos())
+			err = cs.apply(edit{off, off, line + "\n\n"})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return cs.addLiteralEntry(fieldName, varName)
+}
+
+// addLiteralEntry adds `fieldName: value` to the Container literal, below
+// the last module entry.
+func (cs *containerSource) addLiteralEntry(fieldName, value string) error {
+	b, err := cs.builder()
+	if err != nil {
+		return err
+	}
+	lit := b.lit
+	if lit == nil {
+		return cs.errorf(b.fn, "cannot find the Container{...} literal; add `%s: %s` by hand", fieldName, value)
+	}
+
+	moduleFields := cs.moduleFields()
+	entry := fieldName + ": " + value
+
+	var anchor ast.Expr
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return cs.errorf(lit, "the Container literal uses positional fields; add `%s` by hand", entry)
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		if key != nil && key.Name == fieldName {
+			return nil
+		}
+		if key != nil && moduleFields[key.Name] != "" || anchor == nil {
+			anchor = elt
+		} else if anchorKey, _ := anchor.(*ast.KeyValueExpr).Key.(*ast.Ident); anchorKey == nil || moduleFields[anchorKey.Name] == "" {
+			anchor = elt
+		}
+	}
+
+	switch {
+	case anchor == nil:
+		off := cs.offset(lit.Rbrace)
+		return cs.apply(edit{off, off, entry})
+	case cs.line(lit.Elts[len(lit.Elts)-1].End()) == cs.line(lit.Rbrace):
+		// Single-line literal: extend it in place.
+		off := cs.offset(lit.Rbrace)
+		return cs.apply(edit{off, off, ", " + entry})
+	default:
+		return cs.insertAfter(anchor, lit.Rbrace, "\t"+entry+",")
+	}
+}
+
+// sharedValue is infrastructure that NewContainer creates once and hands to
+// the modules or workers that need it, like the job queue or the token
+// issuer. It is also kept on the Container for main.go and hand-written code.
+type sharedValue struct {
+	field      string // Container field, e.g. Jobs
+	fieldType  string
+	variable   string // NewContainer variable, e.g. jobs
+	init       string // expression that creates it
+	comment    string
+	importPath string
+}
+
+// addSharedValue declares the value at the top of the function that builds
+// the Container, and keeps it in a field next to the other non-module
+// fields.
+func (cs *containerSource) addSharedValue(v sharedValue) error {
+	structType := cs.containerStruct()
+	if structType == nil {
+		return fmt.Errorf("%s: cannot find the Container struct; declare `type Container struct { ... }`", containerPath)
+	}
+
+	var anchor *ast.Field
+	for _, field := range structType.Fields.List {
+		if _, ok := modulePackage(field.Type); ok {
+			break
+		}
+		for _, name := range field.Names {
+			if name.Name == v.field {
+				return nil
+			}
+		}
+		anchor = field
+	}
 
-			// Check if field already exists
-			for _, field := range structType.Fields.List {
+	line := "\t" + v.field + " " + v.fieldType
+	var err error
+	if anchor == nil {
+		off := cs.offset(structType.Fields.Opening) + 1
+		err = cs.apply(edit{off, off, "\n" + line})
+	} else {
+		err = cs.insertAfter(anchor, structType.Fields.Closing, line)
+	}
+	if err != nil {
+		return err
+	}
+
+	b, err := cs.builder()
+	if err != nil {
+		return fmt.Errorf("%w; add %s by hand", err, v.field)
+	}
+	if b.lit == nil {
+		return cs.errorf(b.fn, "cannot find the Container{...} literal; add `%s: %s` by hand", v.field, v.variable)
+	}
+
+	var entry ast.Expr
+	moduleFields := cs.moduleFields()
+	for _, elt := range b.lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && moduleFields[key.Name] != "" {
+				break
+			}
+		}
+		entry = elt
+	}
+
+	value := v.field + ": " + v.variable
+	off := cs.offset(b.lit.Lbrace) + 1
+	switch {
+	case len(b.lit.Elts) == 0:
+		err = cs.apply(edit{off, off, value})
+	case cs.line(b.lit.Elts[len(b.lit.Elts)-1].End()) == cs.line(b.lit.Rbrace):
+		// Single-line literal: extend it in place.
+		err = cs.apply(edit{off, off, value + ", "})
+	case entry == nil:
+		err = cs.apply(edit{off, off, "\n" + value + ","})
+	default:
+		err = cs.insertAfter(entry, b.lit.Rbrace, "\t"+value+",")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The builder moved with the edits above; find it again.
+	if b, err = cs.builder(); err != nil {
+		return err
+	}
+	off = cs.offset(b.fn.Body.Lbrace) + 1
+	declaration := fmt.Sprintf("\n\t// %s\n\t%s := %s\n", v.comment, v.variable, v.init)
+	if err := cs.apply(edit{off, off, declaration}); err != nil {
+		return err
+	}
+
+	return cs.addImport(v.importPath)
+}
+
+// provideLogger gives NewContainer a logger for modules that take one, in
+// projects generated before the logger was passed to NewContainer.
+func (cs *containerSource) provideLogger(moduleName string) error {
+	if !constructorTakes(moduleName, "*slog.Logger") {
+		return nil
+	}
+	return cs.addLogger()
+}
+
+// addLogger makes a logger variable available in NewContainer: its logger
+// parameter, or slog.Default() kept in a Logger field.
+func (cs *containerSource) addLogger() error {
+	if fn := cs.function("NewContainer"); fn != nil {
+		for _, param := range fn.Type.Params.List {
+			if arg, _ := paramArg(param.Type); arg == "logger" {
+				return nil
+			}
+		}
+	}
+
+	return cs.addSharedValue(sharedValue{
+		field:      "Logger",
+		fieldType:  "*slog.Logger",
+		variable:   "logger",
+		init:       "slog.Default()",
+		comment:    "Logger for the modules, as configured by main.go",
+		importPath: "log/slog",
+	})
+}
+
+// moduleFields maps the Container fields that hold modules to their package.
+func (cs *containerSource) moduleFields() map[string]string {
+	fields := make(map[string]string)
+	if structType := cs.containerStruct(); structType != nil {
+		for _, field := range structType.Fields.List {
+			if pkg, ok := modulePackage(field.Type); ok {
 				for _, name := range field.Names {
-					if name.Name == fieldName {
-						return // Field already exists
-					}
+					fields[name.Name] = pkg
 				}
 			}
+		}
+	}
+	return fields
+}
 
-			// Add new field
-			newField := &ast.Field{
-				Names: []*ast.Ident{ast.NewIdent(fieldName)},
-				Type:  parseTypeExpr(fieldType),
+func (cs *containerSource) hasInlineModules(lit *ast.CompositeLit) bool {
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if _, ok := constructorCall(kv.Value); ok {
+				return true
 			}
+		}
+	}
+	return false
+}
 
-			structType.Fields.List = append(structType.Fields.List, newField)
-			return
+// constructs reports whether fn calls the module's New<X>Module.
+func (cs *containerSource) constructs(fn *ast.FuncDecl, moduleName string) bool {
+	if fn == nil {
+		return false
+	}
+
+	found := false
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if pkg, ok := constructorCall(n); ok && pkg == moduleName {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// statementHolding returns the top-level statement of fn that contains node.
+func (cs *containerSource) statementHolding(fn *ast.FuncDecl, node ast.Node) ast.Stmt {
+	for _, stmt := range fn.Body.List {
+		if stmt.Pos() <= node.Pos() && node.End() <= stmt.End() {
+			return stmt
 		}
 	}
+	return fn.Body.List[len(fn.Body.List)-1]
 }
 
-func (u *ContainerUpdater) addModuleToConstructor(file *ast.File, moduleVarName, moduleName, moduleNameTitle string) {
-	for _, decl := range file.Decls {
-		funcDecl, ok := decl.(*ast.FuncDecl)
-		if !ok || funcDecl.Name.Name != "NewContainer" {
+// enclosingBlock returns the closing brace of the function body holding stmt.
+func (cs *containerSource) enclosingBlock(stmt ast.Stmt) token.Pos {
+	for _, decl := range cs.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil && fn.Pos() <= stmt.Pos() && stmt.End() <= fn.End() {
+			return fn.Body.Rbrace
+		}
+	}
+	return token.NoPos
+}
+
+// lastModuleAssignment returns the last `c.XModule = x.NewXModule(...)`
+// statement in fn, if the constructor is written that way.
+func (cs *containerSource) lastModuleAssignment(fn *ast.FuncDecl) *ast.AssignStmt {
+	var last *ast.AssignStmt
+	for _, stmt := range fn.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
 			continue
 		}
+		if _, ok := assign.Lhs[0].(*ast.SelectorExpr); !ok {
+			continue
+		}
+		if _, ok := constructorCall(assign.Rhs[0]); ok {
+			last = assign
+		}
+	}
+	return last
+}
+
+// containerLiteral finds the Container{...} literal built by fn, following
+// calls to other functions declared in container.go. It also returns the
+// function the literal was found in.
+func (cs *containerSource) containerLiteral(fn *ast.FuncDecl, visited map[string]bool) (*ast.CompositeLit, *ast.FuncDecl) {
+	visited[fn.Name.Name] = true
+
+	var lit *ast.CompositeLit
+	var helpers []string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if lit != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if ident, ok := n.Type.(*ast.Ident); ok && ident.Name == "Container" {
+				lit = n
+				return false
+			}
+		case *ast.CallExpr:
+			if ident, ok := n.Fun.(*ast.Ident); ok {
+				helpers = append(helpers, ident.Name)
+			}
+		}
+		return true
+	})
+	if lit != nil {
+		return lit, fn
+	}
 
-		// Find the return statement
-		for _, stmt := range funcDecl.Body.List {
-			retStmt, ok := stmt.(*ast.ReturnStmt)
+	for _, name := range helpers {
+		helper := cs.function(name)
+		if helper == nil || visited[name] {
+			continue
+		}
+		if lit, holder := cs.containerLiteral(helper, visited); lit != nil {
+			return lit, holder
+		}
+	}
+
+	return nil, nil
+}
+
+func (cs *containerSource) addRouteRegistration(fieldName string) error {
+	fn := cs.method("RegisterRoutes")
+	if fn == nil {
+		return fmt.Errorf("%s: cannot find method (c *Container) RegisterRoutes", containerPath)
+	}
+	if len(fn.Recv.List[0].Names) == 0 {
+		return cs.errorf(fn, "RegisterRoutes has an unnamed receiver; name it to let gozilla register routes")
+	}
+	recv := fn.Recv.List[0].Names[0].Name
+
+	var anchor ast.Stmt
+	var group string
+	registered := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.ExprStmt:
+			field, ok := routeRegistration(stmt.X)
 			if !ok {
-				continue
+				return true
+			}
+			if field == fieldName {
+				registered = true
+			}
+			if call := stmt.X.(*ast.CallExpr); len(call.Args) == 1 {
+				anchor, group = stmt, cs.text(call.Args[0])
 			}
 
-			for _, result := range retStmt.Results {
-				composite, ok := result.(*ast.UnaryExpr)
-				if !ok {
-					continue
+		case *ast.AssignStmt:
+			// Fall back to the route group, e.g. `api := r.Group("/api/v1")`.
+			if anchor != nil || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+				return true
+			}
+			call, ok := stmt.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" {
+				if ident, ok := stmt.Lhs[0].(*ast.Ident); ok {
+					anchor, group = stmt, ident.Name
 				}
+			}
+		}
+		return true
+	})
 
-				compositeLit, ok := composite.X.(*ast.CompositeLit)
-				if !ok {
-					continue
-				}
+	if registered {
+		return nil
+	}
+	if anchor == nil {
+		return cs.errorf(fn, "cannot find a route group in RegisterRoutes; expected `api := r.Group(...)`")
+	}
 
-				// Check if field already exists
-				for _, elt := range compositeLit.Elts {
-					kv, ok := elt.(*ast.KeyValueExpr)
-					if !ok {
-						continue
-					}
-					if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == moduleVarName {
-						return // Already exists
-					}
-				}
+	line := fmt.Sprintf("\t%s.%s.RegisterRoutes(%s)", recv, fieldName, group)
+	return cs.insertAfter(anchor, fn.Body.Rbrace, line)
+}
 
-				// Add new field initialization
-				newElt := &ast.KeyValueExpr{
-					Key: ast.NewIdent(moduleVarName),
-					Value: &ast.CallExpr{
-						Fun: &ast.SelectorExpr{
-							X:   ast.NewIdent(moduleName),
-							Sel: ast.NewIdent("New" + moduleNameTitle + "Module"),
-						},
-						Args: []ast.Expr{ast.NewIdent("db")},
-					},
-				}
+// registrar is a Container method, other than RegisterRoutes, that hands
+// each module the server of one transport, or the event bus. The module
+// method has the same name and parameters.
+type registrar struct {
+	method     string
+	importPath string // relative to the module path when local
+	params     string
+	args       string
+	local      bool
+}
+
+// registrars lists them; the event bus is imported from the project itself.
+func registrars() []registrar {
+	return []registrar{
+		{"RegisterGRPC", "google.golang.org/grpc", "s *grpc.Server", "s", false},
+		{"RegisterGraphQL", "github.com/graphql-go/graphql", "queries, mutations graphql.Fields", "queries, mutations", false},
+		{"RegisterSubscribers", "internal/domain/events", "bus events.Bus", "bus", true},
+	}
+}
 
-				compositeLit.Elts = append(compositeLit.Elts, newElt)
-				return
+// addRegistration makes the registrar's Container method register the
+// module, adding the method when this is the first module to need it.
+func (cs *containerSource) addRegistration(r registrar, fieldName string) error {
+	fn := cs.method(r.method)
+	if fn == nil {
+		importPath := r.importPath
+		if r.local {
+			modulePath, err := templates.GetModulePath()
+			if err != nil {
+				return err
 			}
+			importPath = modulePath + "/" + importPath
+		}
+		if err := cs.addImport(importPath); err != nil {
+			return err
 		}
+
+		recv := "c"
+		if routes := cs.method("RegisterRoutes"); routes != nil && len(routes.Recv.List[0].Names) > 0 {
+			recv = routes.Recv.List[0].Names[0].Name
+		}
+
+		method := fmt.Sprintf("\nfunc (%s *Container) %s(%s) {\n\t%s.%s.%s(%s)\n}\n",
+			recv, r.method, r.params, recv, fieldName, r.method, r.args)
+		return cs.apply(edit{len(cs.src), len(cs.src), method})
+	}
+
+	if len(fn.Recv.List[0].Names) == 0 {
+		return cs.errorf(fn, "%s has an unnamed receiver; name it to let gozilla register modules", r.method)
 	}
+	recv := fn.Recv.List[0].Names[0].Name
+
+	var args []string
+	for _, param := range fn.Type.Params.List {
+		for _, name := range param.Names {
+			args = append(args, name.Name)
+		}
+	}
+
+	var anchor ast.Stmt
+	for _, stmt := range fn.Body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		if field, ok := moduleCall(exprStmt.X, r.method); ok {
+			if field == fieldName {
+				return nil
+			}
+			anchor = stmt
+		}
+	}
+
+	line := fmt.Sprintf("\t%s.%s.%s(%s)", recv, fieldName, r.method, strings.Join(args, ", "))
+	if anchor == nil {
+		return cs.addFirstStatement(fn, line)
+	}
+	return cs.insertAfter(anchor, fn.Body.Rbrace, line)
+}
+
+// addCloser lists the module first in the closeModules call of
+// Container.Close, which stops modules in the reverse order of their
+// construction. Containers without one, from before graceful shutdown, are
+// left alone.
+func (cs *containerSource) addCloser(fieldName string) error {
+	fn := cs.method("Close")
+	if fn == nil || len(fn.Recv.List[0].Names) == 0 {
+		return nil
+	}
+	recv := fn.Recv.List[0].Names[0].Name
+
+	call := closeModulesCall(fn)
+	if call == nil || len(call.Args) == 0 {
+		return nil
+	}
+
+	entry := recv + "." + fieldName
+	for _, arg := range call.Args[1:] {
+		if cs.text(arg) == entry {
+			return nil
+		}
+	}
+
+	if len(call.Args) == 1 {
+		off := cs.offset(call.Args[0].End())
+		return cs.apply(edit{off, off, ",\n" + entry + ",\n"})
+	}
+	first := call.Args[1]
+	if cs.line(first.Pos()) == cs.line(call.Args[0].End()) {
+		off := cs.offset(first.Pos())
+		return cs.apply(edit{off, off, entry + ", "})
+	}
+	off := cs.lineStart(first.Pos())
+	return cs.apply(edit{off, off, "\t\t" + entry + ",\n"})
+}
+
+// closeModulesCall returns the closeModules(ctx, modules...) call in fn.
+func closeModulesCall(fn *ast.FuncDecl) *ast.CallExpr {
+	var call *ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok && call == nil {
+			if ident, ok := c.Fun.(*ast.Ident); ok && ident.Name == "closeModules" {
+				call = c
+			}
+		}
+		return call == nil
+	})
+	return call
+}
+
+// addFirstStatement adds line to the empty body of fn.
+func (cs *containerSource) addFirstStatement(fn *ast.FuncDecl, line string) error {
+	off := cs.offset(fn.Body.Lbrace) + 1
+	if cs.line(fn.Body.Lbrace) == cs.line(fn.Body.Rbrace) {
+		return cs.apply(edit{off, off, "\n" + line + "\n"})
+	}
+	return cs.apply(edit{off, off, "\n" + line})
 }
 
-func (u *ContainerUpdater) addRouteRegistration(file *ast.File, moduleVarName string) {
+// moduleMethods returns the methods declared in a module's <name>.module.go,
+// which tell the transports it serves. Modules whose file can't be read are
+// assumed to serve HTTP only, like every module did before --transport.
+func moduleMethods(moduleName string) map[string]bool {
+	moduleFile := filepath.Join(modulesDir, moduleName, moduleName+".module.go")
+
+	file, err := parser.ParseFile(token.NewFileSet(), moduleFile, nil, 0)
+	if err != nil {
+		return map[string]bool{"RegisterRoutes": true}
+	}
+
+	methods := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv != nil {
+			methods[funcDecl.Name.Name] = true
+		}
+	}
+	return methods
+}
+
+// constructorArgs works out the arguments for a module's New<X>Module call
+// from its signature. Modules it depends on are passed as returned by
+// moduleRef. Parameters gozilla does not know how to provide are taken from
+// fallback, the arguments the module was previously built with.
+func constructorArgs(moduleName string, fallback []string, moduleRef func(string) (string, error)) ([]string, error) {
+	moduleFile := filepath.Join(modulesDir, moduleName, moduleName+".module.go")
+	constructor := "New" + strings.Title(moduleName) + "Module"
+
+	file, err := parser.ParseFile(token.NewFileSet(), moduleFile, nil, 0)
+	if err != nil {
+		if fallback != nil {
+			return fallback, nil
+		}
+		return nil, fmt.Errorf("failed to parse %s: %w", moduleFile, err)
+	}
+
 	for _, decl := range file.Decls {
 		funcDecl, ok := decl.(*ast.FuncDecl)
-		if !ok || funcDecl.Name.Name != "RegisterRoutes" {
+		if !ok || funcDecl.Recv != nil || funcDecl.Name.Name != constructor {
 			continue
 		}
 
-		// Check if registration already exists
-		for _, stmt := range funcDecl.Body.List {
-			if exprStmt, ok := stmt.(*ast.ExprStmt); ok {
-				if callExpr, ok := exprStmt.X.(*ast.CallExpr); ok {
-					if selExpr, ok := callExpr.Fun.(*ast.SelectorExpr); ok {
-						if x, ok := selExpr.X.(*ast.SelectorExpr); ok {
-							if x.Sel.Name == moduleVarName {
-								return // Already registered
-							}
-						}
-					}
+		args := []string{}
+		for _, param := range funcDecl.Type.Params.List {
+			arg, ok := paramArg(param.Type)
+			if pkg, isModule := modulePackage(param.Type); !ok && isModule {
+				if arg, err = moduleRef(pkg); err != nil {
+					return nil, err
 				}
+				ok = true
+			}
+			if !ok {
+				if fallback != nil {
+					return fallback, nil
+				}
+				return nil, fmt.Errorf("%s: don't know how to provide a %s to %s", moduleFile, types.ExprString(param.Type), constructor)
 			}
-		}
 
-		// Add route registration call
-		newStmt := &ast.ExprStmt{
-			X: &ast.CallExpr{
-				Fun: &ast.SelectorExpr{
-					X: &ast.SelectorExpr{
-						X:   ast.NewIdent("c"),
-						Sel: ast.NewIdent(moduleVarName),
-					},
-					Sel: ast.NewIdent("RegisterRoutes"),
-				},
-				Args: []ast.Expr{ast.NewIdent("api")},
-			},
+			count := len(param.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				args = append(args, arg)
+			}
 		}
 
-		funcDecl.Body.List = append(funcDecl.Body.List, newStmt)
-		return
+		return args, nil
 	}
+
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, fmt.Errorf("%s does not declare %s", moduleFile, constructor)
 }
 
-func parseTypeExpr(typeStr string) ast.Expr {
-	// Simple type expression parser
-	if strings.HasPrefix(typeStr, "*") {
-		return &ast.StarExpr{
-			X: parseTypeExpr(typeStr[1:]),
+// constructorTakes reports whether the module's New<X>Module has a
+// parameter of the given type.
+func constructorTakes(moduleName, paramType string) bool {
+	moduleFile := filepath.Join(modulesDir, moduleName, moduleName+".module.go")
+	constructor := "New" + strings.Title(moduleName) + "Module"
+
+	file, err := parser.ParseFile(token.NewFileSet(), moduleFile, nil, 0)
+	if err != nil {
+		return false
+	}
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Name.Name != constructor {
+			continue
 		}
+		for _, param := range funcDecl.Type.Params.List {
+			if types.ExprString(param.Type) == paramType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// paramArg returns the NewContainer variable that satisfies a module
+// constructor parameter of the given type.
+func paramArg(typ ast.Expr) (string, bool) {
+	switch types.ExprString(typ) {
+	case "*sql.DB":
+		return "db", true
+	case "events.Publisher", "events.Bus":
+		return "bus", true
+	case "events.Transactor":
+		return "transactor", true
+	case "queue.Publisher", "queue.Queue":
+		return "jobs", true
+	case "security.TokenIssuer", "security.TokenVerifier":
+		return "tokens", true
+	case "*config.Config":
+		return "cfg", true
+	case "*slog.Logger":
+		return "logger", true
+	case "*checks.Registry":
+		return "healthChecks", true
 	}
 
-	if strings.Contains(typeStr, ".") {
-		parts := strings.Split(typeStr, ".")
-		return &ast.SelectorExpr{
-			X:   ast.NewIdent(parts[0]),
-			Sel: ast.NewIdent(parts[1]),
+	// Config sections such as config.OrdersConfig are fields of the config
+	if sel, ok := typ.(*ast.SelectorExpr); ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "config" {
+			if section := strings.TrimSuffix(sel.Sel.Name, "Config"); section != "" && section != sel.Sel.Name {
+				return "cfg." + section, true
+			}
 		}
 	}
+	return "", false
+}
+
+// isStandardLibrary reports whether the import path has no domain, like
+// "fmt" or "net/http", and is not part of the project itself.
+func isStandardLibrary(importPath, modulePath string) bool {
+	if importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/") {
+		return false
+	}
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// modulePackage reports the package of a `*pkg.XModule` field type.
+func modulePackage(expr ast.Expr) (string, bool) {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || !strings.HasSuffix(sel.Sel.Name, "Module") {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return pkg.Name, true
+}
 
-	return ast.NewIdent(typeStr)
+// constructorCall reports the package of a `pkg.NewXModule(...)` call.
+func constructorCall(n ast.Node) (string, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !strings.HasPrefix(sel.Sel.Name, "New") || !strings.HasSuffix(sel.Sel.Name, "Module") {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return pkg.Name, true
+}
+
+// routeRegistration reports the field of a `c.XModule.RegisterRoutes(...)` call.
+func routeRegistration(n ast.Node) (string, bool) {
+	return moduleCall(n, "RegisterRoutes")
+}
+
+// moduleCall reports the field of a `c.XModule.<method>(...)` call.
+func moduleCall(n ast.Node, method string) (string, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != method {
+		return "", false
+	}
+	field, ok := sel.X.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	return field.Sel.Name, true
+}
+
+// moduleDefinition reports a `usersModule := users.NewUsersModule(...)`
+// statement, returning the module package and the variable.
+func moduleDefinition(stmt ast.Stmt) (string, string, bool) {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return "", "", false
+	}
+	ident, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	pkg, ok := constructorCall(assign.Rhs[0])
+	if !ok {
+		return "", "", false
+	}
+	return pkg, ident.Name, true
 }
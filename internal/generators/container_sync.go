@@ -0,0 +1,495 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+)
+
+// Sync rebuilds the module wiring in container.go from the modules found
+// under the modules directory, in dependency order. Imports, fields, constructor
+// entries and route registrations that do not belong to a module are kept.
+func (u *ContainerUpdater) Sync() ([]string, error) {
+	modules, err := listModules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules: %w", err)
+	}
+
+	dependencies := make(map[string][]string)
+	for _, moduleName := range modules {
+		deps, err := readModuleDependencies(moduleName)
+		if err != nil {
+			return nil, err
+		}
+		dependencies[moduleName] = deps
+	}
+
+	sorted, err := sortModules(modules, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container.go: %w", err)
+	}
+
+	cs, err := parseContainer(src)
+	if err != nil {
+		return nil, err
+	}
+
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return nil, err
+	}
+	previousArgs, err := cs.rewriteModules(modulesImportPath(modulePath)+"/", sorted)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anything the rewrite could not place (a section that had no module
+	// entries at all) is added the same way `generate module` would.
+	for _, moduleName := range sorted {
+		if err := u.wireModule(cs, moduleName, previousArgs[moduleName]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeFile(containerPath, cs.src); err != nil {
+		return nil, err
+	}
+
+	return sorted, nil
+}
+
+// wiringEntry is one line of module wiring found in container.go.
+type wiringEntry struct {
+	module string
+	node   ast.Node
+	doc    *ast.CommentGroup
+}
+
+// wiringSection is a part of container.go that holds one entry per module:
+// the imports, the Container fields, the constructor, RegisterRoutes, the
+// registrar methods of the other transports and Close.
+type wiringSection struct {
+	entries []wiringEntry
+	line    func(first wiringEntry, moduleName string) string
+}
+
+// rewriteModules regenerates every wiring section in place. The first module
+// entry of a section is replaced by entries for all modules, in order, and
+// the other entries (duplicates and modules that no longer exist) are
+// deleted. Comments attached to an entry travel with its module. It returns
+// the arguments each module was previously constructed with.
+func (cs *containerSource) rewriteModules(modulesPrefix string, modules []string) (map[string][]string, error) {
+	packages := make(map[string]string) // package name -> module name
+	fields := make(map[string]string)   // Container field -> module name
+
+	imports := &wiringSection{line: func(_ wiringEntry, moduleName string) string {
+		return strconv.Quote(modulesPrefix + moduleName)
+	}}
+	imported := make(map[string]bool)
+	if decl := cs.importDecl(); decl != nil {
+		for _, spec := range decl.Specs {
+			imp := spec.(*ast.ImportSpec)
+			importPath, _ := strconv.Unquote(imp.Path.Value)
+
+			name := path.Base(importPath)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			imported[name] = true
+
+			if !strings.HasPrefix(importPath, modulesPrefix) {
+				continue
+			}
+
+			moduleName := strings.SplitN(strings.TrimPrefix(importPath, modulesPrefix), "/", 2)[0]
+			packages[name] = moduleName
+			imports.entries = append(imports.entries, wiringEntry{moduleName, imp, imp.Doc})
+		}
+	}
+
+	// Wiring left behind by a bad merge may use a module package without
+	// importing it.
+	for _, moduleName := range modules {
+		if !imported[moduleName] {
+			packages[moduleName] = moduleName
+		}
+	}
+
+	structFields := &wiringSection{line: func(_ wiringEntry, moduleName string) string {
+		moduleVarName := strings.Title(moduleName) + "Module"
+		return fmt.Sprintf("%s *%s.%s", moduleVarName, moduleName, moduleVarName)
+	}}
+	if structType := cs.containerStruct(); structType != nil {
+		for _, field := range structType.Fields.List {
+			pkg, ok := modulePackage(field.Type)
+			if !ok {
+				continue
+			}
+			if !imported[pkg] && packages[pkg] == "" {
+				packages[pkg] = pkg
+			}
+			if packages[pkg] == "" {
+				continue
+			}
+			for _, name := range field.Names {
+				fields[name.Name] = packages[pkg]
+			}
+			structFields.entries = append(structFields.entries, wiringEntry{packages[pkg], field, field.Doc})
+		}
+	}
+
+	// Calls on a Container field name the module it holds. Calls left behind
+	// by a module whose field is gone name it after the field, so that they
+	// are dropped along with the rest of its wiring.
+	fieldModule := func(field string) string {
+		if fields[field] != "" {
+			return fields[field]
+		}
+		if name, ok := strings.CutSuffix(field, "Module"); ok && name != "" {
+			return strings.ToLower(name)
+		}
+		return ""
+	}
+
+	previousArgs := make(map[string][]string)
+	argsOf := func(moduleName string, call *ast.CallExpr) {
+		args := []string{}
+		for _, arg := range call.Args {
+			args = append(args, cs.text(arg))
+		}
+		previousArgs[moduleName] = args
+	}
+
+	b, err := cs.builder()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sync builds modules in dependency order unless the constructor assigns
+	// them to the Container one by one.
+	style := buildOrdered
+	if b.style == buildAssigned {
+		style = buildAssigned
+	}
+
+	definitions := &wiringSection{}
+	literal := &wiringSection{}
+	assignments := &wiringSection{}
+	routes := &wiringSection{}
+	closers := &wiringSection{}
+	services := make(map[string]*wiringSection) // registrar method -> section
+	for _, r := range registrars() {
+		services[r.method] = &wiringSection{}
+	}
+
+	for _, decl := range cs.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CompositeLit:
+				if ident, ok := n.Type.(*ast.Ident); !ok || ident.Name != "Container" {
+					return true
+				}
+				for _, elt := range n.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					moduleName := ""
+					if pkg, ok := constructorCall(kv.Value); ok && packages[pkg] != "" {
+						moduleName = packages[pkg]
+						argsOf(moduleName, kv.Value.(*ast.CallExpr))
+					} else if key, ok := kv.Key.(*ast.Ident); ok {
+						moduleName = fields[key.Name]
+					}
+					if moduleName != "" {
+						literal.entries = append(literal.entries, wiringEntry{moduleName, kv, cs.leadingComment(kv)})
+					}
+				}
+				return false
+
+			case *ast.AssignStmt:
+				if len(n.Lhs) != 1 || len(n.Rhs) != 1 {
+					return true
+				}
+				pkg, ok := constructorCall(n.Rhs[0])
+				if !ok || packages[pkg] == "" {
+					return true
+				}
+
+				argsOf(packages[pkg], n.Rhs[0].(*ast.CallExpr))
+				entry := wiringEntry{packages[pkg], n, cs.leadingComment(n)}
+				if n.Tok == token.DEFINE {
+					definitions.entries = append(definitions.entries, entry)
+				} else {
+					assignments.entries = append(assignments.entries, entry)
+				}
+				return false
+
+			case *ast.CallExpr:
+				if ident, ok := n.Fun.(*ast.Ident); !ok || ident.Name != "closeModules" || len(n.Args) == 0 {
+					return true
+				}
+				for _, arg := range n.Args[1:] {
+					if sel, ok := arg.(*ast.SelectorExpr); ok && fieldModule(sel.Sel.Name) != "" {
+						closers.entries = append(closers.entries, wiringEntry{fieldModule(sel.Sel.Name), arg, cs.leadingComment(arg)})
+					}
+				}
+				return false
+
+			case *ast.ExprStmt:
+				if field, ok := routeRegistration(n.X); ok && fieldModule(field) != "" {
+					routes.entries = append(routes.entries, wiringEntry{fieldModule(field), n, cs.leadingComment(n)})
+					return false
+				}
+				for _, r := range registrars() {
+					if field, ok := moduleCall(n.X, r.method); ok && fieldModule(field) != "" {
+						services[r.method].entries = append(services[r.method].entries, wiringEntry{fieldModule(field), n, cs.leadingComment(n)})
+						return false
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	ordered := &containerBuilder{style: style, recv: b.recv, vars: make(map[string]string)}
+
+	calls := make(map[string]string)
+	for _, moduleName := range modules {
+		args, err := constructorArgs(moduleName, previousArgs[moduleName], ordered.moduleRef)
+		if err != nil {
+			return nil, err
+		}
+		moduleVarName := strings.Title(moduleName) + "Module"
+		calls[moduleName] = fmt.Sprintf("%s.New%s(%s)", moduleName, moduleVarName, strings.Join(args, ", "))
+	}
+
+	if style == buildAssigned {
+		assignments.line = func(_ wiringEntry, moduleName string) string {
+			return fmt.Sprintf("%s.%s = %s", b.recv, strings.Title(moduleName)+"Module", calls[moduleName])
+		}
+	} else {
+		definitions.line = func(_ wiringEntry, moduleName string) string {
+			return fmt.Sprintf("%sModule := %s", moduleName, calls[moduleName])
+		}
+		literal.line = func(_ wiringEntry, moduleName string) string {
+			return fmt.Sprintf("%sModule: %sModule,", strings.Title(moduleName), moduleName)
+		}
+	}
+
+	routes.line = func(first wiringEntry, moduleName string) string {
+		moduleVarName := strings.Title(moduleName) + "Module"
+		call := first.node.(*ast.ExprStmt).X.(*ast.CallExpr)
+		recv := call.Fun.(*ast.SelectorExpr).X.(*ast.SelectorExpr).X
+
+		group := "api"
+		if len(call.Args) == 1 {
+			group = cs.text(call.Args[0])
+		}
+		return fmt.Sprintf("%s.%s.RegisterRoutes(%s)", cs.text(recv), moduleVarName, group)
+	}
+
+	closers.line = func(first wiringEntry, moduleName string) string {
+		recv := first.node.(*ast.SelectorExpr).X
+		return fmt.Sprintf("%s.%sModule,", cs.text(recv), strings.Title(moduleName))
+	}
+
+	// Modules are only registered on the transports they serve.
+	var httpModules []string
+	serving := make(map[string][]string) // registrar method -> modules
+	for _, moduleName := range modules {
+		methods := moduleMethods(moduleName)
+		if methods["RegisterRoutes"] {
+			httpModules = append(httpModules, moduleName)
+		}
+		for _, r := range registrars() {
+			if methods[r.method] {
+				serving[r.method] = append(serving[r.method], moduleName)
+			}
+		}
+	}
+
+	for _, r := range registrars() {
+		method := r.method
+		services[method].line = func(first wiringEntry, moduleName string) string {
+			call := first.node.(*ast.ExprStmt).X.(*ast.CallExpr)
+			recv := call.Fun.(*ast.SelectorExpr).X.(*ast.SelectorExpr).X
+
+			var args []string
+			for _, arg := range call.Args {
+				args = append(args, cs.text(arg))
+			}
+			return fmt.Sprintf("%s.%sModule.%s(%s)", cs.text(recv), strings.Title(moduleName), method, strings.Join(args, ", "))
+		}
+	}
+
+	var edits []edit
+	for _, section := range []*wiringSection{imports, structFields, definitions, literal, assignments} {
+		edits = append(edits, cs.rewriteSection(section, modules)...)
+	}
+	edits = append(edits, cs.rewriteSection(routes, httpModules)...)
+
+	// Modules are stopped in the reverse order of their construction
+	reversed := slices.Clone(modules)
+	slices.Reverse(reversed)
+	edits = append(edits, cs.rewriteSection(closers, reversed)...)
+	for _, r := range registrars() {
+		edits = append(edits, cs.rewriteSection(services[r.method], serving[r.method])...)
+	}
+
+	// Containers that built modules inline get their construction
+	// statements right before the Container literal.
+	if style == buildOrdered && len(definitions.entries) == 0 && b.lit != nil && len(modules) > 0 {
+		var block strings.Builder
+		for _, moduleName := range modules {
+			block.WriteString(definitions.line(wiringEntry{}, moduleName) + "\n")
+		}
+		off := cs.lineStart(cs.statementHolding(b.fn, b.lit).Pos())
+		edits = append(edits, edit{off, off, block.String() + "\n"})
+	}
+
+	if len(edits) == 0 {
+		return previousArgs, nil
+	}
+
+	return previousArgs, cs.apply(edits...)
+}
+
+func (cs *containerSource) rewriteSection(section *wiringSection, modules []string) []edit {
+	if len(section.entries) == 0 {
+		return nil
+	}
+
+	// A comment opening the section describes the section rather than its
+	// first module, unless it names the entry the way doc comments do: it
+	// stays where it is.
+	header := false
+	if first := &section.entries[0]; first.doc != nil && !cs.namesEntry(first.doc, first.node) {
+		first.doc = nil
+		header = true
+	}
+
+	// Sections written on a single line can't be regenerated line by line;
+	// drop their entries and let wireModule add them back. The same goes
+	// for sections the current construction style doesn't use.
+	for _, entry := range section.entries {
+		if section.line == nil || !cs.ownsLines(entry.node) {
+			var edits []edit
+			for _, entry := range section.entries {
+				edits = append(edits, cs.removal(entry.node, entry.doc))
+			}
+			return edits
+		}
+	}
+
+	type decoration struct {
+		doc, comment string
+	}
+	decorations := make(map[string]decoration)
+	for _, entry := range section.entries {
+		if _, ok := decorations[entry.module]; ok {
+			continue
+		}
+
+		var d decoration
+		if entry.doc != nil {
+			d.doc = cs.text(entry.doc) + "\n"
+		}
+		rest := strings.TrimSpace(string(cs.src[cs.offset(entry.node.End()):cs.lineEnd(entry.node.End())]))
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+		if strings.HasPrefix(rest, "//") {
+			d.comment = " " + rest
+		}
+		decorations[entry.module] = d
+	}
+
+	first := section.entries[0]
+	var block strings.Builder
+	for i, moduleName := range modules {
+		// A blank line keeps the header apart from the doc of the module
+		// now first, so the next sync tells them apart.
+		if i == 0 && header && decorations[moduleName].doc != "" {
+			block.WriteString("\n")
+		}
+		d := decorations[moduleName]
+		block.WriteString(d.doc)
+		block.WriteString(section.line(first, moduleName))
+		block.WriteString(d.comment)
+		block.WriteString("\n")
+	}
+
+	var edits []edit
+	for i, entry := range section.entries {
+		start := cs.lineStart(entry.node.Pos())
+		if entry.doc != nil {
+			start = cs.lineStart(entry.doc.Pos())
+		}
+		end := cs.lineEnd(entry.node.End())
+
+		text := ""
+		if i == 0 {
+			text = block.String()
+		}
+		edits = append(edits, edit{start, end, text})
+	}
+
+	return edits
+}
+
+// ownsLines reports whether node is alone on its lines, apart from a
+// trailing comma and comment.
+func (cs *containerSource) ownsLines(node ast.Node) bool {
+	before := strings.TrimSpace(string(cs.src[cs.lineStart(node.Pos()):cs.offset(node.Pos())]))
+	rest := strings.TrimSpace(string(cs.src[cs.offset(node.End()):cs.lineEnd(node.End())]))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	return before == "" && (rest == "" || strings.HasPrefix(rest, "//"))
+}
+
+// namesEntry reports whether doc starts with one of the identifiers of node,
+// as in "// UsersModule owns the accounts".
+func (cs *containerSource) namesEntry(doc *ast.CommentGroup, node ast.Node) bool {
+	words := strings.Fields(doc.Text())
+	if len(words) == 0 {
+		return false
+	}
+	named := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == words[0] {
+			named = true
+		}
+		return !named
+	})
+	return named
+}
+
+// leadingComment returns the comment lines directly above node, if any.
+func (cs *containerSource) leadingComment(node ast.Node) *ast.CommentGroup {
+	line := cs.line(node.Pos())
+	for _, group := range cs.file.Comments {
+		if cs.line(group.End()) != line-1 {
+			continue
+		}
+		before := strings.TrimSpace(string(cs.src[cs.lineStart(group.Pos()):cs.offset(group.Pos())]))
+		if before == "" {
+			return group
+		}
+	}
+	return nil
+}
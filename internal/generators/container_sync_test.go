@@ -0,0 +1,232 @@
+package generators
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files of testdata with the current output")
+
+// testModule is a module written to the project of a test.
+type testModule struct {
+	name    string
+	depends []string
+}
+
+// moduleSource returns the <name>.module.go of a module whose constructor
+// takes the database, the logger and the modules it depends on.
+func moduleSource(m testModule) string {
+	title := strings.Title(m.name)
+
+	var b strings.Builder
+	if len(m.depends) > 0 {
+		fmt.Fprintf(&b, "%s%s\n\n", dependsDirective, strings.Join(m.depends, ","))
+	}
+	fmt.Fprintf(&b, "package %s\n\nimport (\n\t\"database/sql\"\n\t\"log/slog\"\n\n", m.name)
+	for _, dep := range m.depends {
+		fmt.Fprintf(&b, "\t\"example.com/shop/internal/modules/%s\"\n", dep)
+	}
+	fmt.Fprintf(&b, "\t\"github.com/gin-gonic/gin\"\n)\n\n")
+
+	fmt.Fprintf(&b, "type %sModule struct{}\n\n", title)
+	params := []string{"db *sql.DB", "logger *slog.Logger"}
+	for _, dep := range m.depends {
+		params = append(params, fmt.Sprintf("%sModule *%s.%sModule", dep, dep, strings.Title(dep)))
+	}
+	fmt.Fprintf(&b, "func New%sModule(%s) *%sModule {\n\treturn &%sModule{}\n}\n\n", title, strings.Join(params, ", "), title, title)
+	fmt.Fprintf(&b, "func (m *%sModule) RegisterRoutes(r *gin.RouterGroup) {}\n", title)
+	return b.String()
+}
+
+// enterTestProject makes a temporary project, example.com/shop, the working
+// directory, with the given modules and container.go.
+func enterTestProject(t *testing.T, modules []testModule, container []byte) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() { TakeChanges() })
+
+	files := map[string][]byte{
+		"go.mod":      []byte("module example.com/shop\n\ngo 1.22\n"),
+		containerPath: container,
+	}
+	for _, m := range modules {
+		files[filepath.Join(modulesDir, m.name, m.name+".module.go")] = []byte(moduleSource(m))
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkGolden compares container.go, as left by step, with the golden file,
+// after rewriting it under -update.
+func checkGolden(t *testing.T, golden, step string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("container.go after %s:\n%s\nwant:\n%s", step, got, want)
+	}
+}
+
+func TestSyncContainer(t *testing.T) {
+	users := testModule{name: "users"}
+	orders := testModule{name: "orders", depends: []string{"users"}}
+
+	tests := []struct {
+		name    string
+		modules []testModule
+		order   []string
+	}{
+		// Modules built inside the Container literal are moved to variables
+		// built in dependency order.
+		{"inline_literal", []testModule{users, orders}, []string{"users", "orders"}},
+		{"variable_built", []testModule{users, orders}, []string{"users", "orders"}},
+		{"assigned", []testModule{users, orders}, []string{"users", "orders"}},
+		// Comments above and beside an entry move with its module; the one
+		// opening a section stays on top.
+		{"commented_fields", []testModule{users, orders}, []string{"users", "orders"}},
+		{"duplicates", []testModule{users, orders}, []string{"users", "orders"}},
+		// payments is gone from disk and invoices lost its field in a merge.
+		{"stale_entries", []testModule{users}, []string{"users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := filepath.Abs(filepath.Join("testdata", "sync", tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			input, err := os.ReadFile(filepath.Join(dir, "container.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join(dir, "container.go.golden")
+
+			enterTestProject(t, tt.modules, input)
+
+			order, err := NewContainerUpdater().Sync()
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+			if strings.Join(order, ",") != strings.Join(tt.order, ",") {
+				t.Errorf("Sync() order = %v, want %v", order, tt.order)
+			}
+
+			got, err := os.ReadFile(containerPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, golden, "Sync()", got)
+
+			// A second sync has nothing left to change.
+			if _, err := NewContainerUpdater().Sync(); err != nil {
+				t.Fatalf("second Sync() error = %v", err)
+			}
+			again, err := os.ReadFile(containerPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("second Sync() changed container.go:\n%s", again)
+			}
+		})
+	}
+}
+
+// The container of a new project, with auth registered after health, is
+// sorted without moving the comment that opens the modules.
+func TestSyncGeneratedContainer(t *testing.T) {
+	golden, err := filepath.Abs(filepath.Join("testdata", "sync", "generated", "container.go.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTestProject(t, "example.com/shop", ProjectOptions{Auth: true})
+
+	order, err := NewContainerUpdater().Sync()
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if want := []string{"auth", "health"}; strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Sync() order = %v, want %v", order, want)
+	}
+
+	got, err := os.ReadFile(containerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, "Sync()", got)
+}
+
+func TestAddModule(t *testing.T) {
+	users := testModule{name: "users"}
+	payments := testModule{name: "payments", depends: []string{"users"}}
+	orders := testModule{name: "orders", depends: []string{"users"}}
+	products := testModule{name: "products"}
+
+	tests := []struct {
+		name    string
+		modules []testModule
+		add     string
+	}{
+		// The new module follows the construction style of NewContainer and
+		// receives the module it depends on.
+		{"ordered", []testModule{users, orders}, "orders"},
+		{"assigned", []testModule{users, orders}, "orders"},
+		// Inline modules can't be passed to others: products has no dependencies.
+		{"inline", []testModule{users, products}, "products"},
+		// Entries go below the last module, after its comments.
+		{"commented", []testModule{users, payments, orders}, "orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := filepath.Abs(filepath.Join("testdata", "add", tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			input, err := os.ReadFile(filepath.Join(dir, "container.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			enterTestProject(t, tt.modules, input)
+
+			if err := NewContainerUpdater().AddModule(tt.add); err != nil {
+				t.Fatalf("AddModule(%q) error = %v", tt.add, err)
+			}
+			got, err := os.ReadFile(containerPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join(dir, "container.go.golden"), "AddModule()", got)
+
+			// Adding it again changes nothing.
+			if err := NewContainerUpdater().AddModule(tt.add); err != nil {
+				t.Fatalf("second AddModule(%q) error = %v", tt.add, err)
+			}
+			again, err := os.ReadFile(containerPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("second AddModule() changed container.go:\n%s", again)
+			}
+		})
+	}
+}
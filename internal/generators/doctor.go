@@ -0,0 +1,451 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+)
+
+// Issue is a single problem found by the Doctor, along with a suggested fix.
+type Issue struct {
+	Problem string `json:"problem"`
+	Fix     string `json:"fix"`
+}
+
+type Doctor struct{}
+
+func NewDoctor() *Doctor {
+	return &Doctor{}
+}
+
+// Check inspects the project in the current directory and returns every
+// problem that would stop gozilla from generating code into it.
+func (d *Doctor) Check() ([]Issue, error) {
+	var issues []Issue
+
+	modulePath, err := project.ModulePath("go.mod")
+	if err != nil {
+		return nil, err
+	}
+
+	issues = append(issues, d.checkLayout()...)
+	issues = append(issues, d.checkImportPaths(modulePath)...)
+
+	modules, err := listModules()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	wiring, err := inspectContainer(containerPath)
+	if err != nil {
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("cannot read %s: %v", containerPath, err),
+			Fix:     "restore container.go from version control or recreate it with `gozilla new`",
+		})
+		return issues, nil
+	}
+
+	issues = append(issues, d.checkContainer(wiring, modulePath, modules)...)
+
+	if table, err := ListRoutes(); err == nil {
+		issues = append(issues, d.checkTransports(table.Unguarded)...)
+	}
+
+	return issues, nil
+}
+
+func (d *Doctor) checkLayout() []Issue {
+	var issues []Issue
+
+	// Adopted projects keep their own layout around the parts gozilla needs
+	dirs := projectDirectories
+	if adopted() {
+		dirs = []string{modulesDir, filepath.Dir(containerPath)}
+	}
+
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err == nil && info.IsDir() {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("directory %s is missing", dir),
+			Fix:     fmt.Sprintf("mkdir -p %s", dir),
+		})
+	}
+
+	return issues
+}
+
+// checkImportPaths reports imports of internal packages under another
+// module path, once per wrong path: renaming the module in go.mod breaks
+// every import at once.
+func (d *Doctor) checkImportPaths(modulePath string) []Issue {
+	var issues []Issue
+	files := make(map[string][]string) // wrong module path -> files importing it
+
+	for _, root := range []string{"cmd", "internal"} {
+		filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !strings.HasSuffix(p, ".go") {
+				return nil
+			}
+
+			file, err := parser.ParseFile(token.NewFileSet(), p, nil, parser.ImportsOnly)
+			if err != nil {
+				issues = append(issues, Issue{
+					Problem: fmt.Sprintf("%s does not parse: %v", p, err),
+					Fix:     "fix the syntax error before generating code",
+				})
+				return nil
+			}
+
+			for _, imp := range file.Imports {
+				importPath, _ := strconv.Unquote(imp.Path.Value)
+				idx := strings.Index(importPath, "/internal/")
+				if idx <= 0 || importPath[:idx] == modulePath {
+					continue
+				}
+
+				wrong := importPath[:idx]
+				if !slices.Contains(files[wrong], p) {
+					files[wrong] = append(files[wrong], p)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	for _, wrong := range slices.Sorted(maps.Keys(files)) {
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("%d file(s), such as %s, import packages of %q but go.mod declares module %q", len(files[wrong]), files[wrong][0], wrong, modulePath),
+			Fix:     fmt.Sprintf("replace %q with %q in the imports, or fix the module directive in go.mod", wrong+"/", modulePath+"/"),
+		})
+	}
+
+	return issues
+}
+
+func (d *Doctor) checkContainer(wiring *containerWiring, modulePath string, modules []string) []Issue {
+	var issues []Issue
+
+	if !wiring.hasStruct {
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("%s does not declare a Container struct", containerPath),
+			Fix:     "declare `type Container struct { ... }` holding one field per module",
+		})
+	}
+	if !wiring.hasConstructor {
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("%s does not declare NewContainer", containerPath),
+			Fix:     "declare `func NewContainer(cfg *config.Config, db *sql.DB, logger *slog.Logger) *Container` building every module",
+		})
+	}
+	if !wiring.hasRoutes {
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("%s does not declare Container.RegisterRoutes", containerPath),
+			Fix:     "declare `func (c *Container) RegisterRoutes(r *gin.Engine)` registering every module",
+		})
+	}
+
+	onDisk := make(map[string]bool)
+	for _, moduleName := range modules {
+		onDisk[moduleName] = true
+
+		moduleNameTitle := strings.Title(moduleName)
+		moduleVarName := moduleNameTitle + "Module"
+		importPath := modulesImportPath(modulePath) + "/" + moduleName
+
+		pkg, ok := wiring.packageFor(importPath)
+		if !ok {
+			issues = append(issues, Issue{
+				Problem: fmt.Sprintf("module '%s' is not imported in container.go", moduleName),
+				Fix:     fmt.Sprintf("add import %q to %s", importPath, containerPath),
+			})
+			pkg = moduleName
+		}
+
+		field, ok := wiring.fieldFor(pkg)
+		if !ok {
+			issues = append(issues, Issue{
+				Problem: fmt.Sprintf("module '%s' is not declared in Container", moduleName),
+				Fix:     fmt.Sprintf("add field `%s *%s.%s` to the Container struct", moduleVarName, pkg, moduleVarName),
+			})
+			field = moduleVarName
+		}
+
+		if wiring.constructed[pkg] == 0 {
+			issues = append(issues, Issue{
+				Problem: fmt.Sprintf("module '%s' is not constructed in NewContainer", moduleName),
+				Fix:     "run `gozilla sync container` to build it in NewContainer",
+			})
+		}
+
+		methods := moduleMethods(moduleName)
+		if methods["RegisterRoutes"] && wiring.registered[field] == 0 {
+			issues = append(issues, Issue{
+				Problem: fmt.Sprintf("module '%s' is not registered in RegisterRoutes", moduleName),
+				Fix:     fmt.Sprintf("add `c.%s.RegisterRoutes(api)` to Container.RegisterRoutes", field),
+			})
+		}
+		for _, r := range registrars() {
+			if methods[r.method] && wiring.services[r.method][field] == 0 {
+				issues = append(issues, Issue{
+					Problem: fmt.Sprintf("module '%s' is not registered in %s", moduleName, r.method),
+					Fix:     fmt.Sprintf("add `c.%s.%s(%s)` to Container.%s", field, r.method, r.args, r.method),
+				})
+			}
+		}
+	}
+
+	// And the other way around: wiring that points at modules which are gone.
+	modulesPrefix := modulesImportPath(modulePath) + "/"
+	for _, importPath := range wiring.sortedImports() {
+		if !strings.HasPrefix(importPath, modulesPrefix) {
+			continue
+		}
+
+		moduleName := strings.SplitN(strings.TrimPrefix(importPath, modulesPrefix), "/", 2)[0]
+		if onDisk[moduleName] {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("container.go wires module '%s' but %s does not exist", moduleName, filepath.ToSlash(filepath.Join(modulesDir, moduleName))),
+			Fix:     fmt.Sprintf("remove the %q import and every reference to it from %s, or restore the module", importPath, containerPath),
+		})
+	}
+
+	for _, field := range wiring.sortedFields() {
+		pkg := wiring.fields[field]
+		if _, ok := wiring.imports[pkg]; ok {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("Container field %s uses package '%s' which is not imported", field, pkg),
+			Fix:     fmt.Sprintf("import the '%s' module or remove the field from Container", pkg),
+		})
+	}
+
+	issues = append(issues, d.checkCalls(wiring)...)
+
+	return issues
+}
+
+// checkCalls reports what a bad merge usually leaves in container.go:
+// modules built or registered twice, and calls to modules whose import or
+// field is gone.
+func (d *Doctor) checkCalls(wiring *containerWiring) []Issue {
+	var issues []Issue
+
+	for _, pkg := range slices.Sorted(maps.Keys(wiring.constructed)) {
+		if _, ok := wiring.imports[pkg]; !ok {
+			issues = append(issues, Issue{
+				Problem: fmt.Sprintf("NewContainer builds a module from package '%s' which is not imported", pkg),
+				Fix:     "run `gozilla sync container` to drop modules that no longer exist, or restore the import",
+			})
+			continue
+		}
+		if count := wiring.constructed[pkg]; count > 1 {
+			issues = append(issues, Issue{
+				Problem: fmt.Sprintf("module '%s' is constructed %d times in NewContainer", pkg, count),
+				Fix:     "run `gozilla sync container` to remove the duplicates",
+			})
+		}
+	}
+
+	calls := map[string]map[string]int{"RegisterRoutes": wiring.registered}
+	methods := []string{"RegisterRoutes"}
+	for _, r := range registrars() {
+		calls[r.method] = wiring.services[r.method]
+		methods = append(methods, r.method)
+	}
+	for _, method := range methods {
+		for _, field := range slices.Sorted(maps.Keys(calls[method])) {
+			if !wiring.declared[field] {
+				issues = append(issues, Issue{
+					Problem: fmt.Sprintf("Container.%s calls c.%s.%s but Container has no field %s", method, field, method, field),
+					Fix:     "run `gozilla sync container` to drop modules that no longer exist, or restore the field",
+				})
+				continue
+			}
+			if count := calls[method][field]; count > 1 {
+				issues = append(issues, Issue{
+					Problem: fmt.Sprintf("c.%s is registered %d times in Container.%s", field, count, method),
+					Fix:     "run `gozilla sync container` to remove the duplicates",
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// checkTransports reports the protected modules that another transport
+// serves without a token.
+func (d *Doctor) checkTransports(unguarded []UnguardedTransport) []Issue {
+	var issues []Issue
+	for _, t := range unguarded {
+		issues = append(issues, Issue{
+			Problem: fmt.Sprintf("module '%s' requires a token on its HTTP routes but serves %s without one", t.Module, t.Transport),
+			Fix:     fmt.Sprintf("stop registering the module in Container.Register%s, or check the token in its %s transport", map[string]string{"grpc": "GRPC", "graphql": "GraphQL"}[t.Transport], t.Transport),
+		})
+	}
+	return issues
+}
+
+// containerWiring records what container.go says about each module.
+type containerWiring struct {
+	hasStruct      bool
+	hasConstructor bool
+	hasRoutes      bool
+
+	imports     map[string]string // package name -> import path
+	fields      map[string]string // Container field -> module package
+	declared    map[string]bool   // every Container field
+	constructed map[string]int    // module package -> New<X>Module calls in NewContainer
+	registered  map[string]int    // Container field -> RegisterRoutes calls
+
+	services map[string]map[string]int // registrar method -> Container field -> calls
+}
+
+func inspectContainer(filename string) (*containerWiring, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	wiring := &containerWiring{
+		imports:     make(map[string]string),
+		fields:      make(map[string]string),
+		declared:    make(map[string]bool),
+		constructed: make(map[string]int),
+		registered:  make(map[string]int),
+
+		services: make(map[string]map[string]int),
+	}
+	for _, r := range registrars() {
+		wiring.services[r.method] = make(map[string]int)
+	}
+
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		wiring.imports[name] = importPath
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || typeSpec.Name.Name != "Container" {
+					continue
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+
+				wiring.hasStruct = true
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						wiring.declared[name.Name] = true
+					}
+					pkg, ok := modulePackage(field.Type)
+					if !ok {
+						continue
+					}
+					for _, name := range field.Names {
+						wiring.fields[name.Name] = pkg
+					}
+				}
+			}
+
+		case *ast.FuncDecl:
+			switch {
+			case decl.Recv == nil && decl.Name.Name == "NewContainer":
+				wiring.hasConstructor = true
+				ast.Inspect(decl, func(n ast.Node) bool {
+					if sel, ok := constructorCall(n); ok {
+						wiring.constructed[sel]++
+					}
+					return true
+				})
+
+			case decl.Recv != nil && decl.Name.Name == "RegisterRoutes":
+				wiring.hasRoutes = true
+				ast.Inspect(decl, func(n ast.Node) bool {
+					if field, ok := routeRegistration(n); ok {
+						wiring.registered[field]++
+					}
+					return true
+				})
+
+			case decl.Recv != nil && wiring.services[decl.Name.Name] != nil:
+				method := decl.Name.Name
+				ast.Inspect(decl, func(n ast.Node) bool {
+					if field, ok := moduleCall(n, method); ok {
+						wiring.services[method][field]++
+					}
+					return true
+				})
+			}
+		}
+	}
+
+	return wiring, nil
+}
+
+func (w *containerWiring) packageFor(importPath string) (string, bool) {
+	for name, p := range w.imports {
+		if p == importPath {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (w *containerWiring) fieldFor(pkg string) (string, bool) {
+	for field, p := range w.fields {
+		if p == pkg {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+func (w *containerWiring) sortedImports() []string {
+	var paths []string
+	for _, p := range w.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (w *containerWiring) sortedFields() []string {
+	var fields []string
+	for field := range w.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,204 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+)
+
+var eventsPath = filepath.Join("internal", "domain", "events", "events.go")
+
+// hasEvents reports whether the project has the event bus and outbox that
+// `gozilla new` sets up. Projects created before them publish no events.
+func hasEvents() bool {
+	_, err := os.Stat(eventsPath)
+	return err == nil
+}
+
+type EventGenerator struct{}
+
+func NewEventGenerator() *EventGenerator {
+	return &EventGenerator{}
+}
+
+// Generate declares a custom event in the domain of moduleName and, for each
+// subscriber module, a handler that is registered on the event bus.
+func (g *EventGenerator) Generate(moduleName, eventName string, subscribers []string) error {
+	if !hasEvents() {
+		return fmt.Errorf("the project has no event bus (%s); it is created by `gozilla new`", eventsPath)
+	}
+	if !token.IsIdentifier(eventName) || !token.IsExported(eventName) {
+		return fmt.Errorf("event name '%s' must be an exported Go identifier, like OrderShipped", eventName)
+	}
+
+	for _, name := range append([]string{moduleName}, subscribers...) {
+		if _, err := os.Stat(filepath.Join(modulesDir, name)); os.IsNotExist(err) {
+			return fmt.Errorf("module '%s' does not exist", name)
+		}
+	}
+
+	// Subscribers import the publisher's domain, so they depend on it
+	graph, err := BuildModuleGraph()
+	if err != nil {
+		return fmt.Errorf("failed to build module graph: %w", err)
+	}
+	for _, subscriber := range subscribers {
+		if subscriber == moduleName {
+			continue
+		}
+		if cycle := graph.WouldCycle(subscriber, []string{moduleName}); cycle != nil {
+			return fmt.Errorf("subscribing '%s' would create a dependency cycle: %s", subscriber, strings.Join(cycle, " -> "))
+		}
+	}
+
+	if err := g.declareEvent(moduleName, eventName); err != nil {
+		return err
+	}
+
+	for _, subscriber := range subscribers {
+		if err := g.addSubscriber(subscriber, moduleName, eventName); err != nil {
+			return err
+		}
+		if err := NewContainerUpdater().AddModule(subscriber); err != nil {
+			return fmt.Errorf("failed to update container: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// declareEvent appends the event type to the module's domain/events.go.
+func (g *EventGenerator) declareEvent(moduleName, eventName string) error {
+	path := filepath.Join(modulesDir, moduleName, "domain", "events.go")
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+	data := templates.ModuleData{ModulePath: modulePath, ModulesImportPath: modulesImportPath(modulePath), ModuleName: moduleName}
+
+	src, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		src = []byte(fmt.Sprintf("package domain\n\n// Events published by the %s module.\n", moduleName))
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if file.Scope.Lookup(eventName) != nil {
+		return fmt.Errorf("%s already declares %s", path, eventName)
+	}
+
+	content := append(src, templates.EventTemplate(data, eventName, "ID int64 `json:\"id\"`")...)
+	formatted, err := format.Source(content)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+
+	return writeFile(path, formatted)
+}
+
+// addSubscriber writes the subscriber's handler for the event and subscribes
+// it in the module's RegisterSubscribers, which the container calls.
+func (g *EventGenerator) addSubscriber(subscriber, publisher, eventName string) error {
+	moduleDir := filepath.Join(modulesDir, subscriber)
+	handlerPath := filepath.Join(moduleDir, "application", "subscribers", "on_"+snakeCase(eventName)+".go")
+
+	if _, err := os.Stat(handlerPath); err == nil {
+		return fmt.Errorf("%s already exists", handlerPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(handlerPath), 0755); err != nil {
+		return err
+	}
+
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+	data := templates.ModuleData{ModulePath: modulePath, ModulesImportPath: modulesImportPath(modulePath), ModuleName: subscriber}
+	content := templates.SubscriberTemplate(data, publisher, eventName)
+	if err := writeFile(handlerPath, []byte(content)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", handlerPath, err)
+	}
+
+	moduleFile := filepath.Join(moduleDir, subscriber+".module.go")
+	src, err := os.ReadFile(moduleFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", moduleFile, err)
+	}
+
+	cs, err := parseSource(moduleFile, src)
+	if err != nil {
+		return err
+	}
+
+	domainAlias := publisher + "domain"
+	subscription := fmt.Sprintf("Subscribe(%s.%s{}.EventName(), subscribers.On%s)", domainAlias, eventName, eventName)
+
+	fn := cs.method("RegisterSubscribers")
+	if fn == nil {
+		recv := "m"
+		for _, decl := range cs.file.Decls {
+			if method, ok := decl.(*ast.FuncDecl); ok && method.Recv != nil && len(method.Recv.List[0].Names) > 0 {
+				recv = method.Recv.List[0].Names[0].Name
+				break
+			}
+		}
+
+		method := fmt.Sprintf("\nfunc (%s *%sModule) RegisterSubscribers(bus events.Bus) {\n\tbus.%s\n}\n",
+			recv, strings.Title(subscriber), subscription)
+		err = cs.apply(edit{len(cs.src), len(cs.src), method})
+	} else {
+		params := fn.Type.Params.List
+		if len(params) != 1 || len(params[0].Names) != 1 {
+			return cs.errorf(fn, "RegisterSubscribers should take a single events.Bus; subscribe subscribers.On%s by hand", eventName)
+		}
+		line := "\t" + params[0].Names[0].Name + "." + subscription
+
+		if n := len(fn.Body.List); n > 0 {
+			err = cs.insertAfter(fn.Body.List[n-1], fn.Body.Rbrace, line)
+		} else {
+			err = cs.addFirstStatement(fn, line)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cs.addImport(modulePath + "/internal/domain/events"); err != nil {
+		return err
+	}
+	if err := cs.addImport(modulesImportPath(modulePath) + "/" + subscriber + "/application/subscribers"); err != nil {
+		return err
+	}
+	if err := cs.addNamedImport(domainAlias, modulesImportPath(modulePath)+"/"+publisher+"/domain"); err != nil {
+		return err
+	}
+
+	return writeFile(moduleFile, cs.src)
+}
+
+// snakeCase turns an event name like OrderShipped into order_shipped.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
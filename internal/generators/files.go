@@ -0,0 +1,70 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Changes are the files the generators wrote, and what they could not do,
+// since the last call to TakeChanges.
+type Changes struct {
+	Created  []string
+	Modified []string
+	Warnings []string
+}
+
+var changes Changes
+
+// TakeChanges returns the changes recorded since it was last called and
+// forgets them.
+func TakeChanges() Changes {
+	taken := changes
+	changes = Changes{}
+	return taken
+}
+
+// writeFile writes content to path and records whether the file was created
+// or modified. A file that already holds content is left untouched. Go files
+// are formatted first: where the imports of the project sort among the others
+// depends on its module path, which templates cannot know.
+func writeFile(path string, content []byte) error {
+	if filepath.Ext(path) == ".go" {
+		formatted, err := format.Source(content)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		content = formatted
+	}
+
+	current, readErr := os.ReadFile(path)
+	if readErr == nil && bytes.Equal(current, content) {
+		return nil
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	recordFile(path, os.IsNotExist(readErr))
+	return nil
+}
+
+// recordFile records a file written by a generator, or by a tool it ran.
+// Files created and then modified in the same run count as created.
+func recordFile(path string, created bool) {
+	if slices.Contains(changes.Created, path) || slices.Contains(changes.Modified, path) {
+		return
+	}
+	if created {
+		changes.Created = append(changes.Created, path)
+	} else {
+		changes.Modified = append(changes.Modified, path)
+	}
+}
+
+// warn records something a generator left for the user to do.
+func warn(format string, args ...any) {
+	changes.Warnings = append(changes.Warnings, fmt.Sprintf(format, args...))
+}
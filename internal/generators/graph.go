@@ -0,0 +1,434 @@
+package generators
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+)
+
+// Layers of a module, from the innermost outwards. A package may import
+// packages of its own layer or an inner one, never an outer one.
+var moduleLayers = map[string]int{
+	"domain":      0,
+	"application": 1,
+	"infra":       2,
+	"":            3, // the module package itself (<name>.module.go)
+}
+
+// Dependency is an edge of the module graph.
+type Dependency struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Declared bool   `json:"declared"` // recorded with --depends
+	Imported bool   `json:"imported"` // found in the module's imports
+}
+
+// LayerViolation is an import that breaks the Clean Architecture layering.
+type LayerViolation struct {
+	File   string `json:"file"`
+	Import string `json:"import"`
+	Reason string `json:"reason"`
+}
+
+type ModuleGraph struct {
+	Modules      []string         `json:"modules"`
+	Dependencies []Dependency     `json:"dependencies"`
+	Violations   []LayerViolation `json:"violations"`
+}
+
+// BuildModuleGraph derives module-to-module dependencies from the imports in
+// the modules directory and from the --depends metadata of each module.
+func BuildModuleGraph() (*ModuleGraph, error) {
+	modulePath, err := project.ModulePath("go.mod")
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := listModules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules: %w", err)
+	}
+
+	graph := &ModuleGraph{Modules: modules}
+	edges := make(map[[2]string]*Dependency)
+	edge := func(from, to string) *Dependency {
+		key := [2]string{from, to}
+		if edges[key] == nil {
+			edges[key] = &Dependency{From: from, To: to}
+		}
+		return edges[key]
+	}
+
+	modulesPrefix := modulesImportPath(modulePath) + "/"
+
+	for _, moduleName := range modules {
+		deps, err := readModuleDependencies(moduleName)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deps {
+			edge(moduleName, dep).Declared = true
+		}
+
+		moduleDir := filepath.Join(modulesDir, moduleName)
+		err = filepath.WalkDir(moduleDir, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !strings.HasSuffix(p, ".go") {
+				return err
+			}
+
+			file, err := parser.ParseFile(token.NewFileSet(), p, nil, parser.ImportsOnly)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", p, err)
+			}
+
+			rel, _ := filepath.Rel(moduleDir, filepath.Dir(p))
+			fromLayer := layerOf(filepath.ToSlash(rel))
+
+			for _, imp := range file.Imports {
+				importPath, _ := strconv.Unquote(imp.Path.Value)
+				if !strings.HasPrefix(importPath, modulesPrefix) {
+					continue
+				}
+
+				parts := strings.SplitN(strings.TrimPrefix(importPath, modulesPrefix), "/", 2)
+				target, toLayer := parts[0], ""
+				if len(parts) == 2 {
+					toLayer = layerOf(parts[1])
+				}
+
+				if target != moduleName {
+					edge(moduleName, target).Imported = true
+				}
+
+				if reason := layerViolation(moduleName, fromLayer, target, toLayer); reason != "" {
+					graph.Violations = append(graph.Violations, LayerViolation{
+						File:   p,
+						Import: importPath,
+						Reason: reason,
+					})
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dep := range edges {
+		graph.Dependencies = append(graph.Dependencies, *dep)
+	}
+	sort.Slice(graph.Dependencies, func(i, j int) bool {
+		a, b := graph.Dependencies[i], graph.Dependencies[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	return graph, nil
+}
+
+// layerOf maps a package path inside a module (e.g. "application/usecases")
+// to its layer.
+func layerOf(rel string) string {
+	if rel == "." || rel == "" {
+		return ""
+	}
+	layer := strings.SplitN(rel, "/", 2)[0]
+	if _, ok := moduleLayers[layer]; ok {
+		return layer
+	}
+	return ""
+}
+
+func layerViolation(from, fromLayer, to, toLayer string) string {
+	name := func(layer string) string {
+		if layer == "" {
+			return "module package"
+		}
+		return layer
+	}
+
+	if moduleLayers[fromLayer] < moduleLayers[toLayer] {
+		return fmt.Sprintf("%s of '%s' must not depend on the %s of '%s'", name(fromLayer), from, name(toLayer), to)
+	}
+	if from != to && toLayer == "infra" {
+		return fmt.Sprintf("'%s' reaches into the infrastructure of '%s'", from, to)
+	}
+	return ""
+}
+
+func (g *ModuleGraph) adjacency() map[string][]string {
+	adj := make(map[string][]string)
+	for _, dep := range g.Dependencies {
+		adj[dep.From] = append(adj[dep.From], dep.To)
+	}
+	return adj
+}
+
+// Cycles returns every group of modules that depend on each other, each as
+// a path that starts and ends on the same module.
+func (g *ModuleGraph) Cycles() [][]string {
+	adj := g.adjacency()
+
+	// Tarjan's strongly connected components.
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	var visit func(string)
+	visit = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adj[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] != index[v] {
+			return
+		}
+
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(adj[v], v) {
+			components = append(components, component)
+		}
+	}
+
+	nodes := g.nodes()
+	for _, v := range nodes {
+		if _, seen := index[v]; !seen {
+			visit(v)
+		}
+	}
+
+	var cycles [][]string
+	for _, component := range components {
+		sort.Strings(component)
+		start := component[0]
+		if path := g.findPath(adj, start, start, component); path != nil {
+			cycles = append(cycles, path)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+
+	return cycles
+}
+
+// WouldCycle reports the cycle that adding a module with the given
+// dependencies would create, or nil if there is none.
+func (g *ModuleGraph) WouldCycle(moduleName string, dependencies []string) []string {
+	extended := &ModuleGraph{Dependencies: append([]Dependency(nil), g.Dependencies...)}
+	for _, dep := range dependencies {
+		extended.Dependencies = append(extended.Dependencies, Dependency{From: moduleName, To: dep, Declared: true})
+	}
+
+	return extended.findPath(extended.adjacency(), moduleName, moduleName, nil)
+}
+
+// findPath returns a path from -> ... -> to with at least one edge, staying
+// inside within when it is given.
+func (g *ModuleGraph) findPath(adj map[string][]string, from, to string, within []string) []string {
+	visited := make(map[string]bool)
+
+	var walk func(string) []string
+	walk = func(v string) []string {
+		targets := append([]string(nil), adj[v]...)
+		sort.Strings(targets)
+		for _, w := range targets {
+			if within != nil && !slices.Contains(within, w) {
+				continue
+			}
+			if w == to {
+				return []string{v, w}
+			}
+			if visited[w] {
+				continue
+			}
+			visited[w] = true
+			if rest := walk(w); rest != nil {
+				return append([]string{v}, rest...)
+			}
+		}
+		return nil
+	}
+
+	return walk(from)
+}
+
+func (g *ModuleGraph) nodes() []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			nodes = append(nodes, name)
+		}
+	}
+
+	for _, moduleName := range g.Modules {
+		add(moduleName)
+	}
+	for _, dep := range g.Dependencies {
+		add(dep.From)
+		add(dep.To)
+	}
+
+	sort.Strings(nodes)
+	return nodes
+}
+
+// ModuleNode is a module with the modules it depends on and the modules that
+// depend on it.
+type ModuleNode struct {
+	Name       string   `json:"name"`
+	Depends    []string `json:"depends"`
+	Dependents []string `json:"dependents"`
+}
+
+// GraphReport is the graph as `graph --output json` prints it. Its lists are
+// empty rather than nil, so they always encode as JSON arrays.
+type GraphReport struct {
+	Modules      []string         `json:"modules"`
+	Nodes        []ModuleNode     `json:"nodes"`
+	Dependencies []Dependency     `json:"dependencies"`
+	Violations   []LayerViolation `json:"violations"`
+	Cycles       [][]string       `json:"cycles"`
+}
+
+// Report returns the graph with the direct dependencies and dependents of
+// every module, and its cycles.
+func (g *ModuleGraph) Report() GraphReport {
+	report := GraphReport{
+		Modules:      append([]string{}, g.Modules...),
+		Nodes:        []ModuleNode{},
+		Dependencies: append([]Dependency{}, g.Dependencies...),
+		Violations:   append([]LayerViolation{}, g.Violations...),
+		Cycles:       append([][]string{}, g.Cycles()...),
+	}
+
+	for _, name := range g.nodes() {
+		node := ModuleNode{Name: name, Depends: []string{}, Dependents: []string{}}
+		for _, dep := range g.Dependencies {
+			if dep.From == name {
+				node.Depends = append(node.Depends, dep.To)
+			}
+			if dep.To == name {
+				node.Dependents = append(node.Dependents, dep.From)
+			}
+		}
+		sort.Strings(node.Depends)
+		sort.Strings(node.Dependents)
+		report.Nodes = append(report.Nodes, node)
+	}
+
+	return report
+}
+
+func (g *ModuleGraph) inCycle() map[[2]string]bool {
+	edges := make(map[[2]string]bool)
+	for _, cycle := range g.Cycles() {
+		for i := 0; i+1 < len(cycle); i++ {
+			edges[[2]string{cycle[i], cycle[i+1]}] = true
+		}
+	}
+	return edges
+}
+
+// DOT renders the graph in Graphviz format. Dependencies that were declared
+// with --depends but are not imported yet are dashed; cycles are red.
+func (g *ModuleGraph) DOT() string {
+	cyclic := g.inCycle()
+
+	var b strings.Builder
+	b.WriteString("digraph modules {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n\n")
+
+	for _, name := range g.nodes() {
+		fmt.Fprintf(&b, "  %q;\n", name)
+	}
+	if len(g.Dependencies) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, dep := range g.Dependencies {
+		var attrs []string
+		if !dep.Imported {
+			attrs = append(attrs, "style=dashed")
+		}
+		if cyclic[[2]string{dep.From, dep.To}] {
+			attrs = append(attrs, "color=red")
+		}
+
+		fmt.Fprintf(&b, "  %q -> %q", dep.From, dep.To)
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, with the same
+// conventions as DOT.
+func (g *ModuleGraph) Mermaid() string {
+	cyclic := g.inCycle()
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	for _, name := range g.nodes() {
+		fmt.Fprintf(&b, "  %s[%s]\n", name, name)
+	}
+
+	var red []string
+	for i, dep := range g.Dependencies {
+		arrow := "-->"
+		if !dep.Imported {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", dep.From, arrow, dep.To)
+
+		if cyclic[[2]string{dep.From, dep.To}] {
+			red = append(red, strconv.Itoa(i))
+		}
+	}
+
+	if len(red) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red\n", strings.Join(red, ","))
+	}
+
+	return b.String()
+}
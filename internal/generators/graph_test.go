@@ -0,0 +1,132 @@
+package generators
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// graphOf builds a graph of the given modules and {from, to} edges.
+func graphOf(modules []string, edges ...[2]string) *ModuleGraph {
+	g := &ModuleGraph{Modules: modules}
+	for _, e := range edges {
+		g.Dependencies = append(g.Dependencies, Dependency{From: e[0], To: e[1], Declared: true})
+	}
+	return g
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph *ModuleGraph
+		want  [][]string
+	}{
+		{
+			name:  "no dependencies",
+			graph: graphOf([]string{"orders", "users"}),
+			want:  nil,
+		},
+		{
+			name:  "chain",
+			graph: graphOf(nil, [2]string{"billing", "orders"}, [2]string{"orders", "users"}),
+			want:  nil,
+		},
+		{
+			name:  "diamond",
+			graph: graphOf(nil, [2]string{"d", "b"}, [2]string{"d", "c"}, [2]string{"b", "a"}, [2]string{"c", "a"}),
+			want:  nil,
+		},
+		{
+			name:  "self dependency",
+			graph: graphOf(nil, [2]string{"users", "users"}),
+			want:  [][]string{{"users", "users"}},
+		},
+		{
+			name:  "two modules",
+			graph: graphOf(nil, [2]string{"orders", "users"}, [2]string{"users", "orders"}),
+			want:  [][]string{{"orders", "users", "orders"}},
+		},
+		{
+			name:  "three modules",
+			graph: graphOf(nil, [2]string{"c", "a"}, [2]string{"a", "b"}, [2]string{"b", "c"}),
+			want:  [][]string{{"a", "b", "c", "a"}},
+		},
+		{
+			name: "cycle reached from outside",
+			graph: graphOf(nil,
+				[2]string{"x", "a"}, [2]string{"a", "b"}, [2]string{"b", "a"}, [2]string{"b", "y"}),
+			want: [][]string{{"a", "b", "a"}},
+		},
+		{
+			name: "separate cycles",
+			graph: graphOf(nil,
+				[2]string{"d", "c"}, [2]string{"c", "d"}, [2]string{"a", "b"}, [2]string{"b", "a"}, [2]string{"b", "c"}),
+			want: [][]string{{"a", "b", "a"}, {"c", "d", "c"}},
+		},
+		{
+			// One component, reported once, as the first way back to a
+			name: "overlapping cycles",
+			graph: graphOf(nil,
+				[2]string{"a", "b"}, [2]string{"b", "a"}, [2]string{"b", "c"}, [2]string{"c", "a"}),
+			want: [][]string{{"a", "b", "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.graph.Cycles(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cycles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWouldCycle(t *testing.T) {
+	g := graphOf(nil, [2]string{"orders", "users"}, [2]string{"billing", "orders"})
+
+	tests := []struct {
+		module       string
+		dependencies []string
+		want         []string
+	}{
+		{"shipping", []string{"orders"}, nil},
+		{"users", []string{"billing"}, []string{"users", "billing", "orders", "users"}},
+		{"users", []string{"users"}, []string{"users", "users"}},
+	}
+
+	for _, tt := range tests {
+		if got := g.WouldCycle(tt.module, tt.dependencies); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WouldCycle(%q, %v) = %v, want %v", tt.module, tt.dependencies, got, tt.want)
+		}
+	}
+}
+
+func TestReportEncodesEmptyLists(t *testing.T) {
+	g := graphOf([]string{"health", "orders", "users"}, [2]string{"orders", "users"})
+
+	data, err := json.Marshal(g.Report())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "null") {
+		t.Errorf("report has null lists: %s", data)
+	}
+
+	want := []ModuleNode{
+		{Name: "health", Depends: []string{}, Dependents: []string{}},
+		{Name: "orders", Depends: []string{"users"}, Dependents: []string{}},
+		{Name: "users", Depends: []string{}, Dependents: []string{"orders"}},
+	}
+	if got := g.Report().Nodes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Report().Nodes = %v, want %v", got, want)
+	}
+
+	empty, err := json.Marshal((&ModuleGraph{}).Report())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"modules":[],"nodes":[],"dependencies":[],"violations":[],"cycles":[]}`; string(empty) != want {
+		t.Errorf("empty report = %s, want %s", empty, want)
+	}
+}
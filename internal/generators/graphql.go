@@ -0,0 +1,78 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var (
+	graphqlHandlerPath = filepath.Join("internal", "infrastructure", "graphql", "handler.go")
+	httpServerPath     = filepath.Join("internal", "infrastructure", "http", "server.go")
+)
+
+// setupGraphQL prepares the project for its first GraphQL module: a handler
+// merging every module's fragment into one schema, served at /graphql by the
+// HTTP server, and the GraphQL module in go.mod. Projects that already have
+// them are left untouched.
+func setupGraphQL() error {
+	data, err := projectData()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(graphqlHandlerPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(graphqlHandlerPath), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(graphqlHandlerPath, []byte(projecttemplates.GraphQLHandlerTemplate(data))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", graphqlHandlerPath, err)
+		}
+	}
+
+	if err := requireFeature("graphql"); err != nil {
+		return err
+	}
+
+	return serveGraphQL(data.ModulePath + "/internal/infrastructure/graphql")
+}
+
+// serveGraphQL mounts the GraphQL handler in the HTTP server's setupRoutes.
+func serveGraphQL(importPath string) error {
+	src, err := os.ReadFile(httpServerPath)
+	if err != nil {
+		return fmt.Errorf("failed to read server.go: %w", err)
+	}
+
+	cs, err := parseSource(httpServerPath, src)
+	if err != nil {
+		return err
+	}
+
+	for _, imp := range cs.file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+			return nil
+		}
+	}
+
+	fn := cs.method("setupRoutes")
+	if fn == nil {
+		return fmt.Errorf("%s: cannot find (s *Server) setupRoutes; serve graphql.NewHandler(container.RegisterGraphQL) at /graphql by hand", httpServerPath)
+	}
+
+	off := cs.offset(fn.Body.Rbrace)
+	if err := cs.apply(edit{off, off, projecttemplates.GraphQLRoutesBlock()}); err != nil {
+		return err
+	}
+
+	for _, p := range []string{"log/slog", "os", importPath} {
+		if err := cs.addImport(p); err != nil {
+			return err
+		}
+	}
+
+	return writeFile(httpServerPath, cs.src)
+}
@@ -0,0 +1,396 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var (
+	grpcServerPath = filepath.Join("internal", "infrastructure", "grpc", "server.go")
+	configPath     = filepath.Join("internal", "infrastructure", "config", "config.go")
+	mainPath       = filepath.Join("cmd", "api", "main.go")
+)
+
+// setupGRPC prepares the project for its first gRPC module: a gRPC server
+// next to the HTTP one, a GRPC_PORT setting, a `make proto` target and the
+// gRPC modules in go.mod. Projects that already have them are left untouched.
+func setupGRPC() error {
+	data, err := projectData()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(grpcServerPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(grpcServerPath), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(grpcServerPath, []byte(projecttemplates.GRPCServerTemplate(data))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", grpcServerPath, err)
+		}
+	}
+
+	if err := addGRPCPort(); err != nil {
+		return err
+	}
+
+	if err := startGRPCServer(data.ModulePath + "/internal/infrastructure/grpc"); err != nil {
+		return err
+	}
+
+	if err := appendIfMissing("Makefile", "\nproto:", projecttemplates.ProtoMakeTarget()); err != nil {
+		return err
+	}
+
+	if err := requireFeature("grpc"); err != nil {
+		return err
+	}
+
+	return appendIfMissing(".env.example", "GRPC_PORT=", "GRPC_PORT=9090\n")
+}
+
+// addGRPCPort adds the GRPCPort setting to config.Config and Load.
+func addGRPCPort() error {
+	return addConfigSettings("Port", configSetting{"GRPCPort", "string", `getEnv("GRPC_PORT", "9090")`})
+}
+
+// configSetting is a config.Config field and the expression Load fills it
+// with.
+type configSetting struct {
+	field     string
+	fieldType string
+	value     string
+}
+
+// addConfigSettings adds settings to config.Config and Load, below the
+// field named after or, when there is none, below the last field that is
+// not a section. Settings already there are left alone.
+func addConfigSettings(after string, settings ...configSetting) error {
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config.go: %w", err)
+	}
+
+	cs, err := parseSource(configPath, src)
+	if err != nil {
+		return err
+	}
+
+	if after == "" {
+		after = lastConfigSetting(cs)
+	}
+	for _, setting := range settings {
+		if err := cs.addConfigSetting("Config", after, setting); err != nil {
+			return err
+		}
+		after = setting.field
+	}
+
+	return writeFile(configPath, cs.src)
+}
+
+// addConfigGroup adds settings to config.Config and Load as a group of their
+// own, under comment.
+func addConfigGroup(comment string, settings ...configSetting) error {
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config.go: %w", err)
+	}
+
+	cs, err := parseSource(configPath, src)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.addConfigGroup(comment, settings...); err != nil {
+		return err
+	}
+
+	return writeFile(configPath, cs.src)
+}
+
+// addConfigSetting adds a setting to the struct named structName, which is
+// config.Config or one of its sections, and to the literal Load builds it
+// with.
+func (cs *containerSource) addConfigSetting(structName, after string, setting configSetting) error {
+	structType := cs.structType(structName)
+	if structType == nil {
+		return fmt.Errorf("%s: cannot find the %s struct; add a %s setting by hand", configPath, structName, setting.field)
+	}
+
+	var anchor *ast.Field
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == setting.field {
+				return nil
+			}
+			if name.Name == after || anchor == nil || after == "" {
+				anchor = field
+			}
+		}
+	}
+
+	line := "\t" + setting.field + " " + setting.fieldType
+	var err error
+	if anchor == nil {
+		off := cs.offset(structType.Fields.Closing)
+		err = cs.apply(edit{off, off, "\n" + line + "\n"})
+	} else {
+		err = cs.insertAfter(anchor, structType.Fields.Closing, line)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Fill it in Load, next to the setting it follows.
+	lit := cs.configLiteral(structName)
+	if lit == nil || !cs.declaresConfig("getEnv") {
+		return fmt.Errorf("%s: cannot find where Load builds the %s; set %s to %s by hand", configPath, structName, setting.field, setting.value)
+	}
+
+	entry := setting.field + ": " + cs.configRead(setting.value) + ","
+	var anchorElt ast.Expr
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && (key.Name == after || anchorElt == nil || after == "") {
+				anchorElt = elt
+			}
+		}
+	}
+
+	if anchorElt == nil || cs.line(anchorElt.End()) == cs.line(lit.Rbrace) {
+		off := cs.offset(lit.Rbrace)
+		return cs.apply(edit{off, off, "\n" + entry + "\n"})
+	}
+	return cs.insertAfter(anchorElt, lit.Rbrace, "\t"+entry)
+}
+
+// configLiteral returns the literal Load builds the struct named structName
+// with.
+func (cs *containerSource) configLiteral(structName string) *ast.CompositeLit {
+	var lit *ast.CompositeLit
+	ast.Inspect(cs.file, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CompositeLit); ok && lit == nil {
+			if ident, ok := c.Type.(*ast.Ident); ok && ident.Name == structName {
+				lit = c
+			}
+		}
+		return lit == nil
+	})
+	return lit
+}
+
+// startGRPCServer makes main.go start the gRPC server right before the HTTP
+// server, and shut it down right after it.
+func startGRPCServer(importPath string) error {
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		return fmt.Errorf("failed to read main.go: %w", err)
+	}
+
+	cs, err := parseSource(mainPath, src)
+	if err != nil {
+		return err
+	}
+
+	for _, imp := range cs.file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+			return nil
+		}
+	}
+
+	fn := cs.function("main")
+	if fn == nil {
+		return fmt.Errorf("%s: cannot find func main; start the gRPC server by hand", mainPath)
+	}
+
+	httpStart := cs.httpServerStart(fn)
+	if httpStart == nil {
+		return cs.errorf(fn, "cannot find where the HTTP server is started; start grpc.NewServer(cfg, container) by hand")
+	}
+
+	start := httpStart.Pos()
+	if doc := cs.leadingComment(httpStart); doc != nil {
+		start = doc.Pos()
+	}
+	off := cs.lineStart(start)
+	if err := cs.apply(edit{off, off, projecttemplates.GRPCMainBlock()}); err != nil {
+		return err
+	}
+
+	// Stop it with the HTTP server, in projects that shut down gracefully
+	fn = cs.function("main")
+	if shutdown, ctx := cs.httpServerShutdown(fn); shutdown != nil {
+		if err := cs.insertAfter(shutdown, fn.Body.Rbrace, fmt.Sprintf("\tgrpcServer.Shutdown(%s)", ctx)); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range []string{"log/slog", "os", importPath} {
+		if err := cs.addImport(p); err != nil {
+			return err
+		}
+	}
+
+	return writeFile(mainPath, cs.src)
+}
+
+// httpServerStart returns the statement of main that creates the HTTP
+// server with http.NewServer.
+func (cs *containerSource) httpServerStart(fn *ast.FuncDecl) ast.Stmt {
+	var httpStart ast.Stmt
+	for _, stmt := range fn.Body.List {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewServer" {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "http" {
+					httpStart = stmt
+				}
+			}
+			return httpStart == nil
+		})
+		if httpStart != nil {
+			break
+		}
+	}
+	return httpStart
+}
+
+// httpServerShutdown returns the statement of main that shuts the HTTP
+// server down, and the context it is given, in projects generated with
+// graceful shutdown.
+func (cs *containerSource) httpServerShutdown(fn *ast.FuncDecl) (ast.Stmt, string) {
+	start, ok := cs.httpServerStart(fn).(*ast.AssignStmt)
+	if !ok || len(start.Lhs) != 1 {
+		return nil, ""
+	}
+	server, ok := start.Lhs[0].(*ast.Ident)
+	if !ok {
+		return nil, ""
+	}
+
+	for _, stmt := range fn.Body.List {
+		var ctx string
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return ctx == ""
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Shutdown" {
+				if recv, ok := sel.X.(*ast.Ident); ok && recv.Name == server.Name {
+					ctx = cs.text(call.Args[0])
+				}
+			}
+			return ctx == ""
+		})
+		if ctx != "" {
+			return stmt, ctx
+		}
+	}
+	return nil, ""
+}
+
+// signalContext returns the name of the context main cancels on
+// SIGINT/SIGTERM, if it has one.
+func (cs *containerSource) signalContext(fn *ast.FuncDecl) string {
+	for _, stmt := range fn.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) == 0 || len(assign.Rhs) != 1 {
+			continue
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NotifyContext" {
+			if ctx, ok := assign.Lhs[0].(*ast.Ident); ok {
+				return ctx.Name
+			}
+		}
+	}
+	return ""
+}
+
+// appendIfMissing appends text to a project file unless it already contains
+// marker. Missing files are left alone, with a warning.
+func appendIfMissing(path, marker, text string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		warn("%s not found, so it was not updated", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	content := string(data)
+	if strings.Contains(content, marker) {
+		return nil
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	return writeFile(path, []byte(content+text))
+}
+
+// addComposeEnvironment passes the variable name through to the environment
+// of the app service in docker-compose.yaml, from the shell or .env. A file
+// without that service or environment is left alone, with a warning.
+func addComposeEnvironment(name string) error {
+	data, err := os.ReadFile(composePath)
+	if os.IsNotExist(err) {
+		warn("%s not found, so %s was not passed to the app service", composePath, name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", composePath, err)
+	}
+
+	indentOf := func(line string) int { return len(line) - len(strings.TrimLeft(line, " ")) }
+
+	lines := strings.Split(string(data), "\n")
+	appIndent, envIndent := -1, -1
+	insert, entry := -1, ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := indentOf(line)
+
+		switch {
+		case trimmed == "app:":
+			appIndent, envIndent = indent, -1
+		case appIndent >= 0 && indent <= appIndent:
+			appIndent, envIndent = -1, -1
+		case appIndent >= 0 && trimmed == "environment:":
+			envIndent = indent
+			insert = i + 1
+			entry = strings.Repeat(" ", indent+2) + name + ": ${" + name + "}"
+		case envIndent >= 0 && indent <= envIndent:
+			envIndent = -1
+		case envIndent >= 0:
+			if strings.HasPrefix(trimmed, name+":") || strings.HasPrefix(trimmed, "- "+name+"=") {
+				return nil
+			}
+			insert = i + 1
+			entry = strings.Repeat(" ", indent) + name + ": ${" + name + "}"
+			if strings.HasPrefix(trimmed, "- ") {
+				entry = strings.Repeat(" ", indent) + "- " + name + "=${" + name + "}"
+			}
+		}
+	}
+	if insert < 0 {
+		warn("%s has no environment for the app service; pass %s to it by hand", composePath, name)
+		return nil
+	}
+
+	lines = slices.Insert(lines, insert, entry)
+	return writeFile(composePath, []byte(strings.Join(lines, "\n")))
+}
@@ -0,0 +1,173 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+// migrationDirs are where projects commonly keep their SQL migrations.
+var migrationDirs = []string{"migrations", "db/migrations", "database/migrations", "sql/migrations"}
+
+// moduleDirs are where projects commonly keep their feature modules.
+var moduleDirs = []string{"internal/modules", "internal/module", "internal/features", "modules"}
+
+type InitGenerator struct{}
+
+func NewInitGenerator() *InitGenerator {
+	return &InitGenerator{}
+}
+
+// Generate sets up the Go module in the working directory for gozilla: it
+// finds the container modules are wired into, or creates one, finds the
+// directory modules live in, or creates internal/modules, pins the modules
+// generated code imports and records the layout in .gozilla.yaml. It reports whether it created the container.
+func (g *InitGenerator) Generate() (ProjectConfig, bool, error) {
+	if _, err := project.ModulePath("go.mod"); err != nil {
+		return ProjectConfig{}, false, err
+	}
+	if adopted() {
+		return ProjectConfig{}, false, fmt.Errorf("%s already exists: the project is set up for gozilla", ProjectConfigFile)
+	}
+
+	container, err := findContainer()
+	if err != nil {
+		return ProjectConfig{}, false, err
+	}
+	created := container == ""
+	if !created && !hasRouteGroup(container) {
+		warn("%s: RegisterRoutes has no route group; add `api := r.Group(\"/api/v1\")` so gozilla can register the routes of modules", container)
+	}
+	if created {
+		container = containerPath
+		if err := os.MkdirAll(filepath.Dir(container), 0755); err != nil {
+			return ProjectConfig{}, false, err
+		}
+		data, err := projectData()
+		if err != nil {
+			return ProjectConfig{}, false, err
+		}
+		if err := writeFile(container, []byte(projecttemplates.InitContainerTemplate(data))); err != nil {
+			return ProjectConfig{}, false, fmt.Errorf("failed to write %s: %w", container, err)
+		}
+	}
+
+	config := ProjectConfig{
+		Container:  filepath.ToSlash(container),
+		Modules:    existingDir(moduleDirs),
+		Migrations: existingDir(migrationDirs),
+	}
+
+	// Keep the directory in git until it holds a module
+	modules := filepath.FromSlash(config.Modules)
+	if _, err := os.Stat(modules); os.IsNotExist(err) {
+		if err := os.MkdirAll(modules, 0755); err != nil {
+			return config, created, err
+		}
+		if err := writeFile(filepath.Join(modules, ".gitkeep"), nil); err != nil {
+			return config, created, err
+		}
+	}
+
+	if err := writeProjectConfig(config); err != nil {
+		return config, created, fmt.Errorf("failed to write %s: %w", ProjectConfigFile, err)
+	}
+	if err := loadProjectConfig(); err != nil {
+		return config, created, err
+	}
+
+	for _, feature := range []string{"http", "postgres"} {
+		if err := requireFeature(feature); err != nil {
+			return config, created, err
+		}
+	}
+
+	return config, created, nil
+}
+
+// existingDir returns the first of dirs that exists, or the first one when
+// none does.
+func existingDir(dirs []string) string {
+	for _, dir := range dirs {
+		if info, err := os.Stat(filepath.FromSlash(dir)); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return dirs[0]
+}
+
+// findContainer returns the file under internal/ that declares a container
+// gozilla can wire modules into, or "" when there is none. A file at the
+// default path must be one.
+func findContainer() (string, error) {
+	if _, err := os.Stat(containerPath); err == nil {
+		if !isContainer(containerPath) {
+			return "", fmt.Errorf("%s is not a container gozilla can wire modules into: it needs a Container struct, a NewContainer function and a (c *Container) RegisterRoutes method", containerPath)
+		}
+		return containerPath, nil
+	}
+
+	found := ""
+	err := filepath.WalkDir("internal", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found != "" {
+			return err
+		}
+		if d.IsDir() && slices.Contains(moduleDirs, filepath.ToSlash(path)) {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") && isContainer(path) {
+			found = path
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return found, nil
+}
+
+// isContainer reports whether the Go file at path declares a Container
+// struct, NewContainer and a RegisterRoutes method.
+func isContainer(path string) bool {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	cs, err := parseSource(path, src)
+	if err != nil {
+		return false
+	}
+	return cs.containerStruct() != nil && cs.function("NewContainer") != nil && cs.method("RegisterRoutes") != nil
+}
+
+// hasRouteGroup reports whether RegisterRoutes in the container at path
+// assigns a route group, which module routes are registered on.
+func hasRouteGroup(path string) bool {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	cs, err := parseSource(path, src)
+	if err != nil {
+		return false
+	}
+	found := false
+	ast.Inspect(cs.method("RegisterRoutes").Body, func(n ast.Node) bool {
+		if stmt, ok := n.(*ast.AssignStmt); ok && len(stmt.Rhs) == 1 {
+			if call, ok := stmt.Rhs[0].(*ast.CallExpr); ok {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,376 @@
+package generators
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var (
+	k8sDir           = filepath.Join("deploy", "k8s", "base")
+	healthRoutesPath = filepath.Join("internal", "modules", "health", "infra", "routes.go")
+)
+
+// configGetters are the functions config.Load reads settings with.
+var configGetters = map[string]bool{
+	"getEnv": true, "getIntEnv": true, "getInt64Env": true, "getBoolEnv": true,
+	"getDurationEnv": true, "getListEnv": true,
+}
+
+type K8sGenerator struct{}
+
+func NewK8sGenerator() *K8sGenerator {
+	return &K8sGenerator{}
+}
+
+// Generate writes Kustomize base manifests to deploy/k8s/base. The ConfigMap and Secret are rebuilt from the settings
+// config.Load reads every time, keeping the values already set in them; the
+// other manifests are left alone once they exist, unless force is set.
+func (g *K8sGenerator) Generate(force bool) error {
+	settings, err := configSettings()
+	if err != nil {
+		return err
+	}
+
+	configMapPath := filepath.Join(k8sDir, "configmap.yaml")
+	secretPath := filepath.Join(k8sDir, "secret.yaml")
+	current := readK8sValues(configMapPath)
+	for key, value := range readK8sValues(secretPath) {
+		current[key] = value
+	}
+
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+	data := projecttemplates.K8sData{
+		Name:      k8sName(modulePath),
+		Port:      "8080",
+		LivePath:  "/api/v1/health",
+		ReadyPath: "/api/v1/health",
+		Metrics:   hasTelemetry(),
+	}
+	if routes, err := os.ReadFile(healthRoutesPath); err == nil && strings.Contains(string(routes), `"/ready"`) {
+		data.LivePath, data.ReadyPath = "/api/v1/health/live", "/api/v1/health/ready"
+	}
+
+	shutdown, drain := 20*time.Second, 5*time.Second
+	for _, setting := range settings {
+		value := setting.Value
+		if v, ok := current[setting.Key]; ok {
+			value = v
+		}
+
+		switch setting.Key {
+		case "PORT":
+			data.Port = value
+		case "GRPC_PORT":
+			data.GRPCPort = value
+		case "SHUTDOWN_TIMEOUT":
+			if d, err := time.ParseDuration(value); err == nil {
+				shutdown = d
+			}
+		case "SHUTDOWN_DRAIN_PERIOD":
+			if d, err := time.ParseDuration(value); err == nil {
+				drain = d
+			}
+		}
+
+		if secretSetting(setting.Key) {
+			if _, ok := current[setting.Key]; !ok {
+				value = "change-me"
+			}
+			if value == "change-me" {
+				warn("%s in %s is a placeholder; set the real value out of git", setting.Key, secretPath)
+			}
+			data.Secrets = append(data.Secrets, projecttemplates.K8sValue{Key: setting.Key, Value: value})
+		} else {
+			data.Config = append(data.Config, projecttemplates.K8sValue{Key: setting.Key, Value: value})
+		}
+	}
+	data.GracePeriod = int((drain + shutdown).Seconds()) + 10
+
+	if err := os.MkdirAll(k8sDir, 0755); err != nil {
+		return err
+	}
+
+	files := []struct {
+		path       string
+		content    string
+		regenerate bool
+	}{
+		{filepath.Join(k8sDir, "kustomization.yaml"), projecttemplates.K8sKustomizationTemplate(data), false},
+		{configMapPath, projecttemplates.K8sConfigMapTemplate(data), true},
+		{secretPath, projecttemplates.K8sSecretTemplate(data), true},
+		{filepath.Join(k8sDir, "deployment.yaml"), projecttemplates.K8sDeploymentTemplate(data), false},
+		{filepath.Join(k8sDir, "service.yaml"), projecttemplates.K8sServiceTemplate(data), false},
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file.path); err == nil && !file.regenerate && !force {
+			continue
+		}
+		if err := writeFile(file.path, []byte(file.content)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+	}
+
+	return nil
+}
+
+// configSettings returns the environment variables config.go reads, in
+// order, with their defaults. Defaults that are not constants are taken
+// from .env.example.
+func configSettings() ([]projecttemplates.K8sValue, error) {
+	src, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config.go: %w", err)
+	}
+	cs, err := parseSource(configPath, src)
+	if err != nil {
+		return nil, err
+	}
+	examples := readEnvFile(".env.example")
+
+	var settings []projecttemplates.K8sValue
+	seen := make(map[string]bool)
+	ast.Inspect(cs.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		if !configGetters[calledName(call)] {
+			return true
+		}
+
+		key, ok := configKey(call.Args[0])
+		if !ok || seen[key] {
+			return true
+		}
+		seen[key] = true
+
+		value, ok := configValue(call.Args[1])
+		if !ok {
+			value = examples[key]
+		}
+		if key == "ENVIRONMENT" {
+			value = "production"
+		}
+		settings = append(settings, projecttemplates.K8sValue{Key: key, Value: value})
+		return true
+	})
+
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("%s reads no settings with getEnv", configPath)
+	}
+	return settings, nil
+}
+
+// calledName returns the name of the function or method call calls, such as
+// getEnv for getEnv(...) and s.getEnv(...).
+func calledName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		return fn.Sel.Name
+	}
+	return ""
+}
+
+// configKey returns the environment variable of a getter's first argument,
+// "KEY" or required("KEY").
+func configKey(expr ast.Expr) (string, bool) {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if calledName(call) == "required" {
+			expr = call.Args[0]
+		}
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	key, err := strconv.Unquote(lit.Value)
+	return key, err == nil
+}
+
+// configValue writes the default of a setting as it would be set in the
+// environment: 30*time.Second is 30s, []string{"a", "b"} is a,b.
+func configValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			value, err := strconv.Unquote(e.Value)
+			return value, err == nil
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false":
+			return e.Name, true
+		case "nil":
+			return "", true
+		}
+	case *ast.CompositeLit:
+		var items []string
+		for _, elt := range e.Elts {
+			item, ok := configValue(elt)
+			if !ok {
+				return "", false
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), true
+	}
+
+	n, duration, ok := configNumber(expr)
+	if !ok {
+		return "", false
+	}
+	if duration {
+		return durationValue(time.Duration(n)), true
+	}
+	return strconv.FormatInt(n, 10), true
+}
+
+// durationValue writes d in the largest unit that divides it, such as 60s
+// as 1m, falling back to its String form (1m30s).
+func durationValue(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}, {time.Millisecond, "ms"},
+	}
+	for _, u := range units {
+		if d != 0 && d%u.unit == 0 {
+			return strconv.FormatInt(int64(d/u.unit), 10) + u.name
+		}
+	}
+	return d.String()
+}
+
+// configNumber evaluates integer and duration constants such as 1<<20 and
+// 30*24*time.Hour, reporting whether the result is a duration.
+func configNumber(expr ast.Expr) (int64, bool, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.INT {
+			n, err := strconv.ParseInt(e.Value, 0, 64)
+			return n, false, err == nil
+		}
+	case *ast.ParenExpr:
+		return configNumber(e.X)
+	case *ast.SelectorExpr:
+		units := map[string]time.Duration{
+			"Nanosecond": time.Nanosecond, "Microsecond": time.Microsecond, "Millisecond": time.Millisecond,
+			"Second": time.Second, "Minute": time.Minute, "Hour": time.Hour,
+		}
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "time" {
+			if unit, ok := units[e.Sel.Name]; ok {
+				return int64(unit), true, true
+			}
+		}
+	case *ast.BinaryExpr:
+		x, xDuration, ok := configNumber(e.X)
+		if !ok {
+			return 0, false, false
+		}
+		y, yDuration, ok := configNumber(e.Y)
+		if !ok {
+			return 0, false, false
+		}
+		duration := xDuration || yDuration
+		switch e.Op {
+		case token.MUL:
+			return x * y, duration, true
+		case token.ADD:
+			return x + y, duration, true
+		case token.SUB:
+			return x - y, duration, true
+		case token.SHL:
+			return x << y, duration, true
+		}
+	}
+	return 0, false, false
+}
+
+// secretSetting reports whether a setting holds a credential, which goes in
+// the Secret rather than the ConfigMap.
+func secretSetting(key string) bool {
+	if key == "DATABASE_URL" {
+		return true
+	}
+	for _, word := range []string{"SECRET", "PASSWORD", "TOKEN", "API_KEY", "PRIVATE_KEY"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+var k8sValueLine = regexp.MustCompile(`^  ([A-Za-z_][A-Za-z0-9_]*): (".*")$`)
+
+// readK8sValues reads the values of a ConfigMap or Secret written by
+// Generate, so that running it again keeps them.
+func readK8sValues(path string) map[string]string {
+	values := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if m := k8sValueLine.FindStringSubmatch(scanner.Text()); m != nil {
+			if value, err := strconv.Unquote(m[2]); err == nil {
+				values[m[1]] = value
+			}
+		}
+	}
+	return values
+}
+
+// readEnvFile reads the KEY=value lines of a .env file.
+func readEnvFile(path string) map[string]string {
+	values := make(map[string]string)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return values
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// k8sName turns the last element of the module path into a Kubernetes
+// resource name: lowercase letters, digits and dashes.
+func k8sName(modulePath string) string {
+	base := strings.ToLower(filepath.Base(modulePath))
+	name := strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(base, "-"), "-")
+	if name == "" {
+		return "app"
+	}
+	return name
+}
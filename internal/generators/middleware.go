@@ -0,0 +1,168 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var middlewareDir = filepath.Join("internal", "infrastructure", "http", "middleware")
+
+type MiddlewareGenerator struct{}
+
+func NewMiddlewareGenerator() *MiddlewareGenerator {
+	return &MiddlewareGenerator{}
+}
+
+// Generate scaffolds a gin middleware in internal/infrastructure/http/middleware
+// and returns the path of its file. With global set it is also added to the
+// middleware every route runs through, in the HTTP server's setupRoutes.
+func (g *MiddlewareGenerator) Generate(name string, global bool) (string, error) {
+	funcName := pascalCase(name)
+	if funcName == "" || !token.IsIdentifier(funcName) {
+		return "", fmt.Errorf("invalid middleware name '%s'", name)
+	}
+
+	path := filepath.Join(middlewareDir, snakeCase(funcName)+".go")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	if declared, err := middlewareDeclared(funcName); err != nil {
+		return "", err
+	} else if declared {
+		return "", fmt.Errorf("middleware.%s is already declared", funcName)
+	}
+
+	project, err := projectData()
+	if err != nil {
+		return "", err
+	}
+	content := projecttemplates.CustomMiddlewareTemplate(project, funcName)
+	if formatted, err := format.Source([]byte(content)); err == nil {
+		content = string(formatted)
+	}
+
+	if err := os.MkdirAll(middlewareDir, 0755); err != nil {
+		return "", err
+	}
+	if err := writeFile(path, []byte(content)); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if global {
+		if err := useMiddleware(project.ModulePath, funcName); err != nil {
+			return "", fmt.Errorf("failed to register middleware: %w", err)
+		}
+	}
+
+	return path, nil
+}
+
+// middlewareDeclared reports whether the middleware package already has a
+// top-level declaration with the given name.
+func middlewareDeclared(name string) (bool, error) {
+	entries, err := os.ReadDir(middlewareDir)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(middlewareDir, entry.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return false, err
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// useMiddleware appends middleware.<name>() to the s.router.Use(...) call
+// of setupRoutes, or starts setupRoutes with one in servers without it.
+func useMiddleware(modulePath, name string) error {
+	src, err := os.ReadFile(httpServerPath)
+	if err != nil {
+		return fmt.Errorf("failed to read server.go: %w", err)
+	}
+
+	cs, err := parseSource(httpServerPath, src)
+	if err != nil {
+		return err
+	}
+
+	fn := cs.method("setupRoutes")
+	if fn == nil {
+		return fmt.Errorf("%s: cannot find (s *Server) setupRoutes; add router.Use(middleware.%s()) by hand", httpServerPath, name)
+	}
+
+	var use *ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && use == nil {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Use" {
+				use = call
+			}
+		}
+		return true
+	})
+
+	entry := fmt.Sprintf("middleware.%s()", name)
+	switch {
+	case use == nil:
+		err = cs.addFirstStatement(fn, fmt.Sprintf("\ts.router.Use(%s)\n", entry))
+	case len(use.Args) == 0:
+		off := cs.offset(use.Rparen)
+		err = cs.apply(edit{off, off, entry})
+	case cs.line(use.Args[len(use.Args)-1].End()) == cs.line(use.Rparen):
+		off := cs.offset(use.Rparen)
+		err = cs.apply(edit{off, off, ", " + entry})
+	default:
+		// One middleware per line, with a trailing comma
+		err = cs.insertAfter(use.Args[len(use.Args)-1], use.Rparen, "\t\t"+entry+",")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cs.addImport(modulePath + "/internal/infrastructure/http/middleware"); err != nil {
+		return err
+	}
+
+	return writeFile(httpServerPath, cs.src)
+}
+
+// pascalCase turns "rate-limit", "rate_limit" or "rateLimit" into
+// "RateLimit".
+func pascalCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range strings.TrimSpace(name) {
+		if r == '-' || r == '_' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
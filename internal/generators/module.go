@@ -2,27 +2,89 @@ package generators
 
 import (
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
 )
 
+// ModuleTransports are the transports a module can be exposed over.
+var ModuleTransports = []string{"http", "grpc", "graphql"}
+
+// ModuleOptions are the flags of `generate module`.
+type ModuleOptions struct {
+	Dependencies []string
+	Transports   []string
+	Protected    bool
+	Authz        string // --authz policy, see ParseAuthz
+}
+
 type ModuleGenerator struct{}
 
 func NewModuleGenerator() *ModuleGenerator {
 	return &ModuleGenerator{}
 }
 
-func (g *ModuleGenerator) Generate(moduleName string, dependencies []string) error {
+func (g *ModuleGenerator) Generate(moduleName string, opts ModuleOptions) error {
+	dependencies, transports := opts.Dependencies, opts.Transports
+
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return err
+	}
+
 	data := templates.ModuleData{
-		ModuleName:       moduleName,
-		ModuleNameTitle:  strings.Title(moduleName),
-		ModuleNameUpper:  strings.ToUpper(moduleName),
-		EntityName:       strings.TrimSuffix(moduleName, "s"),
-		EntityNamePlural: moduleName,
-		Dependencies:     dependencies,
+		ModulePath:        modulePath,
+		ModulesImportPath: modulesImportPath(modulePath),
+		ModuleName:        moduleName,
+		ModuleNameTitle:   strings.Title(moduleName),
+		ModuleNameUpper:   strings.ToUpper(moduleName),
+		EntityName:        strings.TrimSuffix(moduleName, "s"),
+		EntityNamePlural:  moduleName,
+		Dependencies:      dependencies,
+		Transports:        transports,
+		Events:            hasEvents(),
+		Tracing:           hasTelemetry(),
+		HealthChecks:      hasHealthChecks(),
+		Protected:         opts.Protected,
+	}
+
+	if len(transports) == 0 {
+		return fmt.Errorf("--transport needs at least one of: %s", strings.Join(ModuleTransports, ", "))
+	}
+	for _, transport := range transports {
+		if !slices.Contains(ModuleTransports, transport) {
+			return fmt.Errorf("unknown transport '%s' (expected %s)", transport, strings.Join(ModuleTransports, ", "))
+		}
+	}
+
+	if opts.Authz != "" {
+		authz, err := ParseAuthz(opts.Authz)
+		if err != nil {
+			return err
+		}
+		data.Authz = authz
+		data.Protected = true
+	}
+
+	if data.Protected && !data.Serves("http") {
+		return fmt.Errorf("--protected and --authz need the http transport")
+	}
+	// Only the HTTP middleware checks the token: the gRPC server and the
+	// GraphQL resolvers would hand the same use cases to anyone
+	if data.Protected && (data.Serves("grpc") || data.Serves("graphql")) {
+		return fmt.Errorf("--protected and --authz guard only the http transport; drop grpc and graphql from --transport, which would serve the module without a token")
+	}
+	if data.Serves("grpc") {
+		if err := checkGoVersion("grpc"); err != nil {
+			return err
+		}
+	}
+	if data.Protected && !hasAuth() {
+		return fmt.Errorf("--protected and --authz need the auth middleware; run `gozilla add auth --jwt` first")
 	}
 
 	// Ensure entity name is properly capitalized
@@ -30,10 +92,27 @@ func (g *ModuleGenerator) Generate(moduleName string, dependencies []string) err
 		data.EntityName = strings.ToUpper(data.EntityName[:1]) + data.EntityName[1:]
 	}
 
-	moduleDir := filepath.Join("internal", "modules", moduleName)
+	moduleDir := filepath.Join(modulesDir, moduleName)
+
+	// Refuse dependencies that don't exist or would close an import cycle
+	if len(dependencies) > 0 {
+		for _, dep := range dependencies {
+			if _, err := os.Stat(filepath.Join(modulesDir, dep)); os.IsNotExist(err) {
+				return fmt.Errorf("--depends module '%s' does not exist", dep)
+			}
+		}
+
+		graph, err := BuildModuleGraph()
+		if err != nil {
+			return fmt.Errorf("failed to build module graph: %w", err)
+		}
+		if cycle := graph.WouldCycle(moduleName, dependencies); cycle != nil {
+			return fmt.Errorf("--depends would create a dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
 
 	// Create directory structure
-	if err := g.createDirectories(moduleDir); err != nil {
+	if err := g.createDirectories(moduleDir, data); err != nil {
 		return fmt.Errorf("failed to create directories: %w", err)
 	}
 
@@ -42,6 +121,28 @@ func (g *ModuleGenerator) Generate(moduleName string, dependencies []string) err
 		return fmt.Errorf("failed to generate files: %w", err)
 	}
 
+	// Serve gRPC and GraphQL from the project the first time a module needs them
+	if data.Serves("grpc") {
+		if err := setupGRPC(); err != nil {
+			return fmt.Errorf("failed to set up gRPC server: %w", err)
+		}
+	}
+	if data.Serves("graphql") {
+		if err := setupGraphQL(); err != nil {
+			return fmt.Errorf("failed to set up GraphQL: %w", err)
+		}
+	}
+
+	// Record the roles granted each action in the permission registry
+	if data.Authz != nil {
+		if err := setupAuthz(); err != nil {
+			return fmt.Errorf("failed to set up authorization: %w", err)
+		}
+		if err := registerPermissions(data); err != nil {
+			return fmt.Errorf("failed to register permissions: %w", err)
+		}
+	}
+
 	// Update container
 	containerUpdater := NewContainerUpdater()
 	if err := containerUpdater.AddModule(moduleName); err != nil {
@@ -51,7 +152,7 @@ func (g *ModuleGenerator) Generate(moduleName string, dependencies []string) err
 	return nil
 }
 
-func (g *ModuleGenerator) createDirectories(moduleDir string) error {
+func (g *ModuleGenerator) createDirectories(moduleDir string, data templates.ModuleData) error {
 	dirs := []string{
 		moduleDir,
 		filepath.Join(moduleDir, "domain"),
@@ -60,6 +161,10 @@ func (g *ModuleGenerator) createDirectories(moduleDir string) error {
 		filepath.Join(moduleDir, "infra"),
 	}
 
+	if data.Serves("grpc") {
+		dirs = append(dirs, filepath.Join(moduleDir, "infra", "proto"))
+	}
+
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return err
@@ -89,13 +194,53 @@ func (g *ModuleGenerator) generateFiles(moduleDir string, data templates.ModuleD
 		filepath.Join(moduleDir, "application", "usecases", fmt.Sprintf("delete_%s.go", data.ModuleName)): templates.DeleteUseCaseTemplate(data),
 
 		// Infrastructure layer
-		filepath.Join(moduleDir, "infra", "handler.go"):                                     templates.HandlerTemplate(data),
-		filepath.Join(moduleDir, "infra", "routes.go"):                                      templates.RoutesTemplate(data),
 		filepath.Join(moduleDir, "infra", fmt.Sprintf("%s_repository.go", data.ModuleName)): templates.RepositoryImplTemplate(data),
 	}
 
+	// The repository's table, which its readiness check queries
+	migration, err := nextMigration("create_" + data.ModuleName)
+	if err != nil {
+		return err
+	}
+	files[migration+".up.sql"] = templates.MigrationUpTemplate(data)
+	files[migration+".down.sql"] = templates.MigrationDownTemplate(data)
+
+	// Domain events, in projects with the event bus
+	if data.Events {
+		files[filepath.Join(moduleDir, "domain", "events.go")] = templates.DomainEventsTemplate(data)
+	}
+
+	// Spans around use cases and queries, in projects with telemetry
+	if data.Tracing {
+		files[filepath.Join(moduleDir, "application", "usecases", "tracer.go")] = templates.UseCaseTracerTemplate(data)
+		files[filepath.Join(moduleDir, "infra", "tracing.go")] = templates.RepositoryTracingTemplate(data)
+	}
+
+	// Transports
+	if data.Serves("http") {
+		files[filepath.Join(moduleDir, "infra", "handler.go")] = templates.HandlerTemplate(data)
+		files[filepath.Join(moduleDir, "infra", "routes.go")] = templates.RoutesTemplate(data)
+	}
+	if data.Serves("grpc") {
+		files[filepath.Join(moduleDir, "infra", "grpc_server.go")] = templates.GRPCServerTemplate(data)
+		files[filepath.Join(moduleDir, "infra", "proto", fmt.Sprintf("%s.proto", data.ModuleName))] = templates.ProtoTemplate(data)
+	}
+	if data.Serves("graphql") {
+		files[filepath.Join(moduleDir, "infra", "graphql_schema.go")] = templates.GraphQLSchemaTemplate(data)
+		files[filepath.Join(moduleDir, "infra", "graphql_resolver.go")] = templates.GraphQLResolverTemplate(data)
+	}
+
 	for path, content := range files {
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		if strings.HasSuffix(path, ".go") {
+			if formatted, err := format.Source([]byte(content)); err == nil {
+				content = string(formatted)
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(path, []byte(content)); err != nil {
 			return fmt.Errorf("failed to write %s: %w", path, err)
 		}
 	}
@@ -0,0 +1,135 @@
+package generators
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// dependsDirective marks the dependencies a module was generated with.
+// It lives in the module's <name>.module.go file:
+//
+//	//gozilla:depends users,categories
+const dependsDirective = "//gozilla:depends "
+
+// modulesDir is the directory the modules live in. Projects adopted with
+// `gozilla init` may keep them elsewhere, as recorded in .gozilla.yaml.
+var modulesDir = filepath.Join("internal", "modules")
+
+// ModulesDir returns the directory the modules of the project live in.
+func ModulesDir() string {
+	return modulesDir
+}
+
+// modulesImportPath returns the import path of modulesDir in the project
+// whose go.mod declares modulePath.
+func modulesImportPath(modulePath string) string {
+	return modulePath + "/" + filepath.ToSlash(modulesDir)
+}
+
+// listModules returns the module directories under modulesDir.
+func listModules() ([]string, error) {
+	entries, err := os.ReadDir(modulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			modules = append(modules, entry.Name())
+		}
+	}
+
+	return modules, nil
+}
+
+// readModuleDependencies returns the dependencies recorded in a module's
+// <name>.module.go file. Modules without the file or the directive have none.
+func readModuleDependencies(moduleName string) ([]string, error) {
+	moduleFile := filepath.Join(modulesDir, moduleName, moduleName+".module.go")
+
+	file, err := parser.ParseFile(token.NewFileSet(), moduleFile, nil, parser.ParseComments|parser.PackageClauseOnly)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", moduleFile, err)
+	}
+
+	var dependencies []string
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if !strings.HasPrefix(comment.Text, dependsDirective) {
+				continue
+			}
+			for _, dep := range strings.Split(strings.TrimPrefix(comment.Text, dependsDirective), ",") {
+				if dep = strings.TrimSpace(dep); dep != "" {
+					dependencies = append(dependencies, dep)
+				}
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// sortModules orders modules so that every module comes after the modules
+// it depends on. Ties are broken alphabetically so the result is stable.
+func sortModules(modules []string, dependencies map[string][]string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, moduleName := range modules {
+		known[moduleName] = true
+	}
+
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	for _, moduleName := range modules {
+		for _, dep := range dependencies[moduleName] {
+			if !known[dep] {
+				return nil, fmt.Errorf("module '%s' depends on '%s', which does not exist", moduleName, dep)
+			}
+			pending[moduleName]++
+			dependents[dep] = append(dependents[dep], moduleName)
+		}
+	}
+
+	var ready []string
+	for _, moduleName := range modules {
+		if pending[moduleName] == 0 {
+			ready = append(ready, moduleName)
+		}
+	}
+
+	var sorted []string
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		next := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, next)
+
+		for _, dependent := range dependents[next] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(sorted) != len(modules) {
+		var cyclic []string
+		for _, moduleName := range modules {
+			if pending[moduleName] > 0 {
+				cyclic = append(cyclic, moduleName)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("dependency cycle between modules: %s", strings.Join(cyclic, ", "))
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,78 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortModules(t *testing.T) {
+	tests := []struct {
+		name         string
+		modules      []string
+		dependencies map[string][]string
+		want         []string
+		wantErr      string
+	}{
+		{
+			name:    "independent modules are alphabetical",
+			modules: []string{"users", "billing", "orders"},
+			want:    []string{"billing", "orders", "users"},
+		},
+		{
+			name:         "chain",
+			modules:      []string{"billing", "orders", "users"},
+			dependencies: map[string][]string{"billing": {"orders"}, "orders": {"users"}},
+			want:         []string{"users", "orders", "billing"},
+		},
+		{
+			name:         "diamond",
+			modules:      []string{"d", "c", "b", "a"},
+			dependencies: map[string][]string{"d": {"b", "c"}, "b": {"a"}, "c": {"a"}},
+			want:         []string{"a", "b", "c", "d"},
+		},
+		{
+			// a waits for z; m, ready from the start, goes first
+			name:         "ties are broken alphabetically among ready modules",
+			modules:      []string{"z", "a", "m"},
+			dependencies: map[string][]string{"a": {"z"}},
+			want:         []string{"m", "z", "a"},
+		},
+		{
+			name:         "missing dependency",
+			modules:      []string{"orders"},
+			dependencies: map[string][]string{"orders": {"users"}},
+			wantErr:      "module 'orders' depends on 'users', which does not exist",
+		},
+		{
+			name:         "cycle",
+			modules:      []string{"a", "b", "c"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"a"}},
+			wantErr:      "dependency cycle between modules: a, b",
+		},
+		{
+			// c is stuck behind the cycle and reported with it
+			name:         "dependent of a cycle",
+			modules:      []string{"a", "b", "c"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"a"}, "c": {"a"}},
+			wantErr:      "dependency cycle between modules: a, b, c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortModules(tt.modules, tt.dependencies)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("sortModules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sortModules() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortModules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
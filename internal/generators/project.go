@@ -5,21 +5,67 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	templates "github.com/pierslabs/gozilla-cli/internal/templates/project"
 )
 
+var telemetryPath = filepath.Join("internal", "infrastructure", "telemetry", "telemetry.go")
+
+// hasTelemetry reports whether the project was created with --observability.
+func hasTelemetry() bool {
+	_, err := os.Stat(telemetryPath)
+	return err == nil
+}
+
+var checksPath = filepath.Join("internal", "domain", "checks", "checks.go")
+
+// hasHealthChecks reports whether the project has the health check registry
+// that /health/ready runs.
+func hasHealthChecks() bool {
+	_, err := os.Stat(checksPath)
+	return err == nil
+}
+
+// ProjectOptions are the flags of `new`.
+type ProjectOptions struct {
+	Observability bool
+	CI            string // one of CIProviders, or empty
+	GoVersion     string // go directive of go.mod; the local toolchain's if empty
+	SkipInstall   bool   // leave go mod tidy to the user, to work offline
+	Auth          bool   // add JWT authentication, as `gozilla add auth --jwt`
+}
+
+// Features returns the features of dependencyVersions the options add to
+// the project, beyond those of every project.
+func (o ProjectOptions) Features() []string {
+	var features []string
+	if o.Observability {
+		features = append(features, "observability")
+	}
+	if o.Auth {
+		features = append(features, "auth")
+	}
+	return features
+}
+
 type ProjectGenerator struct{}
 
 func NewProjectGenerator() *ProjectGenerator {
 	return &ProjectGenerator{}
 }
 
-func (g *ProjectGenerator) Generate(projectName, projectDir string) error {
+func (g *ProjectGenerator) Generate(projectName, projectDir string, opts ProjectOptions) error {
 	data := templates.ProjectData{
-		ProjectName: projectName,
-		ModulePath:  projectName,
-		ProjectDir:  projectDir,
+		ProjectName:   projectName,
+		ModulePath:    projectName,
+		ProjectDir:    projectDir,
+		Observability: opts.Observability,
+		CI:            opts.CI,
+		GoVersion:     opts.GoVersion,
+	}
+	if data.GoVersion == "" {
+		data.GoVersion = DefaultGoVersion(opts.Features()...)
 	}
 
 	// Create directory structure
@@ -33,36 +79,88 @@ func (g *ProjectGenerator) Generate(projectName, projectDir string) error {
 	}
 
 	// Initialize go module
-	if err := g.initGoModule(projectDir, projectName); err != nil {
+	if err := g.initGoModule(data); err != nil {
 		return fmt.Errorf("failed to initialize go module: %w", err)
 	}
 
+	if opts.Auth {
+		if err := g.addAuth(projectDir); err != nil {
+			return fmt.Errorf("failed to add auth: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// addAuth runs the auth generator in the new project, which it expects to
+// be the working directory, and records its files under projectDir like
+// the rest of the project's.
+func (g *ProjectGenerator) addAuth(projectDir string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(projectDir); err != nil {
+		return err
+	}
+	defer os.Chdir(wd)
+
+	project := TakeChanges()
+	err = NewAuthGenerator().Generate()
+	auth := TakeChanges()
+	changes = project
+
+	for _, path := range auth.Created {
+		recordFile(filepath.Join(projectDir, path), true)
+	}
+	for _, path := range auth.Modified {
+		recordFile(filepath.Join(projectDir, path), false)
+	}
+	changes.Warnings = append(changes.Warnings, auth.Warnings...)
+	return err
+}
+
+// projectDirectories is the layout created by `gozilla new`. The doctor
+// command checks existing projects against the same list.
+var projectDirectories = []string{
+	".",
+	"cmd/api",
+	"internal/domain",
+	"internal/infrastructure/database",
+	"internal/infrastructure/http",
+	"internal/infrastructure/config",
+	"internal/infrastructure/container",
+	"internal/modules/health/infra",
+	"migrations",
+}
+
+// placeholderDirectories are created empty, for the code to come, with a
+// .gitkeep so that git keeps them. The doctor does not require them: clones
+// of projects generated before they had one lack them.
+var placeholderDirectories = []string{
+	"internal/modules/health/domain",
+	"internal/modules/health/application",
+	"pkg",
+}
+
 func (g *ProjectGenerator) createDirectories(projectDir string) error {
-	paths := []string{
-		".",
-		"cmd/api",
-		"internal/domain",
-		"internal/infrastructure/database",
-		"internal/infrastructure/http",
-		"internal/infrastructure/config",
-		"internal/infrastructure/container",
-		"internal/modules/health/domain",
-		"internal/modules/health/application",
-		"internal/modules/health/infra",
-		"pkg",
-		"migrations",
-	}
-
-	for _, path := range paths {
+	for _, path := range projectDirectories {
 		dir := filepath.Join(projectDir, path)
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return err
 		}
 	}
 
+	for _, path := range placeholderDirectories {
+		dir := filepath.Join(projectDir, path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dir, ".gitkeep"), nil); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -75,24 +173,68 @@ func (g *ProjectGenerator) generateFiles(data templates.ProjectData) error {
 	files := []fileSpec{
 		{"cmd/api/main.go", templates.MainGoTemplate},
 		{"internal/infrastructure/config/config.go", templates.ConfigTemplate},
+		{"internal/infrastructure/logging/logging.go", templates.LoggingTemplate},
+		{"internal/domain/events/events.go", templates.EventsTemplate},
+		{"internal/domain/checks/checks.go", templates.HealthChecksTemplate},
+		{"internal/domain/checks/checks_test.go", templates.HealthChecksTestTemplate},
 		{"internal/infrastructure/database/database.go", templates.DatabaseTemplate},
+		{"internal/infrastructure/database/tx.go", templates.TransactorTemplate},
+		{"internal/infrastructure/database/check.go", templates.DatabaseCheckTemplate},
+		{"internal/infrastructure/outbox/outbox.go", templates.OutboxTemplate},
 		{"internal/infrastructure/http/server.go", templates.ServerTemplate},
+		{"internal/infrastructure/http/middleware/request_id.go", templates.RequestIDMiddlewareTemplate},
+		{"internal/infrastructure/http/middleware/logger.go", templates.LoggerMiddlewareTemplate},
+		{"internal/infrastructure/http/middleware/recovery.go", templates.RecoveryMiddlewareTemplate},
+		{"internal/infrastructure/http/middleware/cors.go", templates.CORSMiddlewareTemplate},
+		{"internal/infrastructure/http/middleware/timeout.go", templates.TimeoutMiddlewareTemplate},
+		{"internal/infrastructure/http/middleware/body_limit.go", templates.BodyLimitMiddlewareTemplate},
+		{"internal/infrastructure/http/middleware/security_headers.go", templates.SecurityHeadersMiddlewareTemplate},
 		{"internal/infrastructure/container/container.go", templates.ContainerTemplate},
 		{"internal/modules/health/health.module.go", templates.HealthModuleTemplate},
 		{"internal/modules/health/infra/handler.go", templates.HealthHandlerTemplate},
 		{"internal/modules/health/infra/routes.go", templates.HealthRoutesTemplate},
-		{"docker-compose.yaml", templates.DockerComposeTemplate},
+		{"migrations/000001_create_outbox.up.sql", templates.OutboxMigrationUpTemplate},
+		{"migrations/000001_create_outbox.down.sql", templates.OutboxMigrationDownTemplate},
+		{"Dockerfile", templates.DockerfileTemplate},
+		{".dockerignore", templates.DockerignoreTemplate},
+		{composePath, templates.DockerComposeTemplate},
 		{"Makefile", templates.MakefileTemplate},
 		{".env.example", templates.EnvExampleTemplate},
+		{"config.yaml", templates.ConfigYAMLTemplate},
+		{"config.development.yaml", templates.ConfigDevelopmentYAMLTemplate},
 		{".gitignore", templates.GitignoreTemplate},
 		{"README.md", templates.ReadmeTemplate},
 	}
 
+	// Tracing and metrics
+	if data.Observability {
+		files = append(files,
+			fileSpec{"internal/infrastructure/telemetry/telemetry.go", templates.TelemetryTemplate},
+			fileSpec{"internal/infrastructure/http/middleware/telemetry.go", templates.TelemetryMiddlewareTemplate},
+		)
+	}
+
+	// CI pipeline
+	if data.CI != "" {
+		pipeline, err := ciFiles(data)
+		if err != nil {
+			return err
+		}
+		for path, content := range pipeline {
+			files = append(files, fileSpec{path, func(templates.ProjectData) string { return content }})
+		}
+	}
+
 	for _, file := range files {
 		fullPath := filepath.Join(data.ProjectDir, file.path)
 		content := file.template(data)
 
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		// Optional features such as the event outbox live outside the
+		// directories every project has.
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(fullPath, []byte(content)); err != nil {
 			return fmt.Errorf("failed to write %s: %w", file.path, err)
 		}
 	}
@@ -100,21 +242,22 @@ func (g *ProjectGenerator) generateFiles(data templates.ProjectData) error {
 	return nil
 }
 
-func (g *ProjectGenerator) initGoModule(projectDir, modulePath string) error {
-	goModPath := filepath.Join(projectDir, "go.mod")
-
-	content := fmt.Sprintf(`module %s
+func (g *ProjectGenerator) initGoModule(data templates.ProjectData) error {
+	goModPath := filepath.Join(data.ProjectDir, "go.mod")
 
-go 1.21
+	features := []string{"http", "postgres", "config"}
+	if data.Observability {
+		features = append(features, "observability")
+	}
 
-require (
-	github.com/gin-gonic/gin v1.10.0
-	github.com/joho/godotenv v1.5.1
-	github.com/lib/pq v1.10.9
-)
-`, modulePath)
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n\ngo %s\n\nrequire (\n", data.ModulePath, data.GoVersion)
+	for _, r := range requirements(features...) {
+		fmt.Fprintf(&b, "\t%s %s\n", r.path, r.version)
+	}
+	b.WriteString(")\n")
 
-	return os.WriteFile(goModPath, []byte(content), 0644)
+	return writeFile(goModPath, []byte(b.String()))
 }
 
 func (g *ProjectGenerator) InstallDependencies(projectDir string) error {
@@ -124,5 +267,6 @@ func (g *ProjectGenerator) InstallDependencies(projectDir string) error {
 	if err != nil {
 		return fmt.Errorf("failed to install dependencies: %w\n%s", err, string(output))
 	}
+	recordFile(filepath.Join(projectDir, "go.sum"), true)
 	return nil
 }
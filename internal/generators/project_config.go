@@ -0,0 +1,95 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+	templates "github.com/pierslabs/gozilla-cli/internal/templates/module"
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile records, in projects adopted with `gozilla init`, where
+// the parts gozilla generates code into live. Projects created with
+// `gozilla new` have none and use the default layout.
+const ProjectConfigFile = project.ConfigFile
+
+// ProjectConfig is the content of .gozilla.yaml.
+type ProjectConfig struct {
+	Container  string `yaml:"container" json:"container"`   // container.go
+	Modules    string `yaml:"modules" json:"modules"`       // directory of the modules
+	Migrations string `yaml:"migrations" json:"migrations"` // directory of the SQL migrations
+}
+
+// EnterProject makes the root of the project the working directory is in
+// the working directory, so that commands run from any of its
+// subdirectories, and loads its layout. It fails outside a Go project and
+// on a go.mod without a module path, which generated imports start with.
+func EnterProject() error {
+	root, err := project.FindRoot(".")
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+	if _, err := project.ModulePath("go.mod"); err != nil {
+		return err
+	}
+	return loadProjectConfig()
+}
+
+// projectData returns the template data of the project in the working
+// directory.
+func projectData() (projecttemplates.ProjectData, error) {
+	modulePath, err := templates.GetModulePath()
+	if err != nil {
+		return projecttemplates.ProjectData{}, err
+	}
+	return projecttemplates.ProjectData{ModulePath: modulePath}, nil
+}
+
+// loadProjectConfig points the generators at the paths .gozilla.yaml maps,
+// when the project in the working directory has one.
+func loadProjectConfig() error {
+	data, err := os.ReadFile(ProjectConfigFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var config ProjectConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("invalid %s: %w", ProjectConfigFile, err)
+	}
+	if config.Container != "" {
+		containerPath = filepath.FromSlash(config.Container)
+	}
+	if config.Modules != "" {
+		modulesDir = filepath.FromSlash(config.Modules)
+	}
+	if config.Migrations != "" {
+		migrationsDir = filepath.FromSlash(config.Migrations)
+	}
+	return nil
+}
+
+// adopted reports whether the project was adopted with `gozilla init`
+// rather than created with `gozilla new`.
+func adopted() bool {
+	_, err := os.Stat(ProjectConfigFile)
+	return err == nil
+}
+
+func writeProjectConfig(config ProjectConfig) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	header := "# Written by gozilla init: where gozilla finds the parts of this project\n# it generates code into.\n"
+	return writeFile(ProjectConfigFile, append([]byte(header), data...))
+}
@@ -0,0 +1,156 @@
+package generators
+
+import (
+	"bytes"
+	"go/format"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+)
+
+// newTestProject generates a project in a temporary directory, without
+// installing its dependencies, and makes it the working directory.
+func newTestProject(t *testing.T, modulePath string, opts ProjectOptions) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() { TakeChanges() })
+
+	opts.SkipInstall = true
+	dir := filepath.Base(modulePath)
+	if err := NewProjectGenerator().Generate(modulePath, dir, opts); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	t.Chdir(dir)
+}
+
+func TestGeneratedCodeIsFormatted(t *testing.T) {
+	// The imports of the project sort after the third-party ones.
+	newTestProject(t, "zeta.io/shop", ProjectOptions{Observability: true, Auth: true})
+
+	if err := NewModuleGenerator().Generate("users", ModuleOptions{Transports: []string{"http"}, Protected: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewModuleGenerator().Generate("orders", ModuleOptions{
+		Dependencies: []string{"users"},
+		Transports:   []string{"http", "grpc", "graphql"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewEventGenerator().Generate("users", "UserBanned", []string{"orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewWorkerGenerator().Generate("mailer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMiddlewareGenerator().Generate("audit", true); err != nil {
+		t.Fatal(err)
+	}
+
+	err := filepath.WalkDir(".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := format.Source(src)
+		if err != nil {
+			t.Errorf("%s does not parse: %v", path, err)
+			return nil
+		}
+		if !bytes.Equal(src, formatted) {
+			t.Errorf("%s is not gofmt-ed", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// goCommand runs the go command in the working directory, offline.
+func goCommand(t *testing.T, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOTOOLCHAIN=local")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if strings.Contains(string(out), "module lookup disabled") {
+			t.Skipf("the dependencies are not in the module cache:\n%s", out)
+		}
+		t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestDrainedProjectIsNotReady(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test on a generated project")
+	}
+	newTestProject(t, "example.com/shop", ProjectOptions{})
+
+	// The generated test makes sure readiness flips once draining starts.
+	goCommand(t, "test", "./internal/domain/checks")
+}
+
+func TestProjectBuildsAtMinimumGoVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+
+	for _, features := range [][]string{nil, {"observability"}, {"auth"}, {"grpc"}, {"graphql"}} {
+		name := strings.Join(features, ",")
+		if name == "" {
+			name = "base"
+		}
+		t.Run(name, func(t *testing.T) {
+			version := MinimumGoVersionFor(features...)
+			newTestProject(t, "example.com/shop", ProjectOptions{
+				GoVersion:     version,
+				Observability: slices.Contains(features, "observability"),
+				Auth:          slices.Contains(features, "auth"),
+			})
+
+			// gRPC modules need protoc: build the server, with the method a
+			// module would add to the container, on the same dependencies
+			if slices.Contains(features, "grpc") {
+				if err := setupGRPC(); err != nil {
+					t.Fatal(err)
+				}
+				src, err := os.ReadFile(containerPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cs, err := parseContainer(src)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := cs.addImport("google.golang.org/grpc"); err != nil {
+					t.Fatal(err)
+				}
+				if err := cs.apply(edit{len(cs.src), len(cs.src), "\nfunc (c *Container) RegisterGRPC(s *grpc.Server) {}\n"}); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(containerPath, cs.src, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if slices.Contains(features, "graphql") {
+				if err := NewModuleGenerator().Generate("products", ModuleOptions{Transports: []string{"graphql"}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			goCommand(t, "mod", "tidy")
+			if got := project.GoVersion("go.mod"); got != version {
+				t.Errorf("go mod tidy raised the go directive to %s, from %s", got, version)
+			}
+			goCommand(t, "build", "./...")
+		})
+	}
+}
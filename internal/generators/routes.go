@@ -0,0 +1,289 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "Any"}
+
+// Route is an HTTP route registered by a module.
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Module     string   `json:"module"`
+	Handler    string   `json:"handler"`
+	Protected  bool     `json:"protected"`  // behind the auth middleware
+	Permission string   `json:"permission"` // checked by middleware.Authorize, if any
+	Roles      []string `json:"roles"`      // granted the permission in security.Permissions
+	Registered bool     `json:"registered"` // the permission is in security.Permissions
+}
+
+type RouteTable struct {
+	Routes []Route `json:"routes"`
+	// Unused are permissions in security.Permissions that no route checks.
+	Unused []string `json:"unused"`
+	// Unguarded are transports serving modules with protected routes.
+	Unguarded []UnguardedTransport `json:"unguarded"`
+}
+
+// UnguardedTransport is a gRPC or GraphQL transport of a module whose HTTP
+// routes require a token. The auth middleware guards HTTP only, so the
+// transport serves the same use cases to anyone.
+type UnguardedTransport struct {
+	Module    string `json:"module"`
+	Transport string `json:"transport"`
+}
+
+// ListRoutes reads the HTTP routes of every module from its
+// infra/routes.go, prefixed with the group the container registers it on,
+// along with the permissions guarding them.
+func ListRoutes() (*RouteTable, error) {
+	modules, err := listModules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules: %w", err)
+	}
+
+	prefixes, err := moduleRoutePrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := readPermissions()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	table := &RouteTable{}
+	checked := make(map[string]bool)
+
+	for _, moduleName := range modules {
+		routesFile := filepath.Join(modulesDir, moduleName, "infra", "routes.go")
+		src, err := os.ReadFile(routesFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		cs, err := parseSource(routesFile, src)
+		if err != nil {
+			return nil, err
+		}
+
+		fn := cs.function("RegisterRoutes")
+		if fn == nil {
+			continue
+		}
+
+		for _, route := range cs.routes(fn, prefixes[moduleName]) {
+			route.Module = moduleName
+			if route.Permission != "" {
+				checked[route.Permission] = true
+				route.Roles, route.Registered = permissions[route.Permission]
+			}
+			table.Routes = append(table.Routes, route)
+		}
+	}
+
+	for permission := range permissions {
+		if !checked[permission] {
+			table.Unused = append(table.Unused, permission)
+		}
+	}
+	sort.Strings(table.Unused)
+
+	table.Unguarded = unguardedTransports(table.Routes)
+
+	return table, nil
+}
+
+// unguardedTransports returns the transports other than HTTP of the modules
+// with protected routes.
+func unguardedTransports(routes []Route) []UnguardedTransport {
+	var protected []string
+	for _, route := range routes {
+		if route.Protected && !slices.Contains(protected, route.Module) {
+			protected = append(protected, route.Module)
+		}
+	}
+	sort.Strings(protected)
+
+	unguarded := []UnguardedTransport{}
+	for _, moduleName := range protected {
+		methods := moduleMethods(moduleName)
+		if methods["RegisterGRPC"] {
+			unguarded = append(unguarded, UnguardedTransport{moduleName, "grpc"})
+		}
+		if methods["RegisterGraphQL"] {
+			unguarded = append(unguarded, UnguardedTransport{moduleName, "graphql"})
+		}
+	}
+	return unguarded
+}
+
+// moduleRoutePrefixes maps each module to the path of the group that
+// Container.RegisterRoutes hands it, e.g. "/api/v1".
+func moduleRoutePrefixes() (map[string]string, error) {
+	src, err := os.ReadFile(containerPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container.go: %w", err)
+	}
+
+	cs, err := parseContainer(src)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := make(map[string]string)
+	fn := cs.method("RegisterRoutes")
+	if fn == nil {
+		return prefixes, nil
+	}
+
+	groups := cs.groupPaths(fn)
+	fields := cs.moduleFields()
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		field, ok := routeRegistration(n)
+		if !ok || len(n.(*ast.CallExpr).Args) == 0 {
+			return true
+		}
+		if group, ok := n.(*ast.CallExpr).Args[0].(*ast.Ident); ok && fields[field] != "" {
+			prefixes[fields[field]] = groups[group.Name].path
+		}
+		return true
+	})
+
+	return prefixes, nil
+}
+
+type routeGroup struct {
+	path       string
+	protected  bool
+	permission string
+}
+
+// groupPaths follows the `x := y.Group("/path", middleware...)` statements
+// of fn, starting from its first parameter.
+func (cs *containerSource) groupPaths(fn *ast.FuncDecl) map[string]routeGroup {
+	groups := make(map[string]routeGroup)
+	if params := fn.Type.Params.List; len(params) > 0 && len(params[0].Names) > 0 {
+		groups[params[0].Names[0].Name] = routeGroup{}
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return true
+		}
+		name, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			return true
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Group" || len(call.Args) == 0 {
+			return true
+		}
+		parent, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		group := groups[parent.Name]
+		group.path = joinRoute(group.path, cs.stringLit(call.Args[0]))
+		group.protected, group.permission = cs.guards(call.Args[1:], group.protected, group.permission)
+		groups[name.Name] = group
+		return true
+	})
+
+	return groups
+}
+
+// routes returns the routes that fn registers, like `g.POST("", handler.Create)`.
+func (cs *containerSource) routes(fn *ast.FuncDecl, prefix string) []Route {
+	groups := cs.groupPaths(fn)
+
+	var routes []Route
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) < 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !slices.Contains(httpMethods, sel.Sel.Name) {
+			return true
+		}
+		recv, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		group, ok := groups[recv.Name]
+		if !ok {
+			return true
+		}
+
+		route := Route{
+			Method:  strings.ToUpper(sel.Sel.Name),
+			Path:    joinRoute(prefix, joinRoute(group.path, cs.stringLit(call.Args[0]))),
+			Handler: cs.text(call.Args[len(call.Args)-1]),
+		}
+		route.Protected, route.Permission = cs.guards(call.Args[1:len(call.Args)-1], group.protected, group.permission)
+		routes = append(routes, route)
+		return true
+	})
+
+	return routes
+}
+
+// guards reports whether the middleware require a token, and the
+// permission they check, on top of what the enclosing group requires.
+func (cs *containerSource) guards(middleware []ast.Expr, protected bool, permission string) (bool, string) {
+	for _, mw := range middleware {
+		name := cs.text(mw)
+		if call, ok := mw.(*ast.CallExpr); ok {
+			name = cs.text(call.Fun)
+			if strings.HasSuffix(name, "Authorize") && len(call.Args) == 1 {
+				permission = cs.stringLit(call.Args[0])
+				continue
+			}
+		}
+		if strings.HasSuffix(name, "requireAuth") || strings.HasSuffix(name, "RequireAuth") {
+			protected = true
+		}
+	}
+	return protected, permission
+}
+
+func (cs *containerSource) stringLit(expr ast.Expr) string {
+	value, err := strconv.Unquote(cs.text(expr))
+	if err != nil {
+		return cs.text(expr)
+	}
+	return value
+}
+
+func joinRoute(prefix, p string) string {
+	if p == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	joined := path.Join(prefix, p)
+	if !strings.HasPrefix(joined, "/") {
+		joined = "/" + joined
+	}
+	return joined
+}
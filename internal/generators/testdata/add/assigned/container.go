@@ -0,0 +1,26 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB          *sql.DB
+	Logger      *slog.Logger
+	UsersModule *users.UsersModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	c := &Container{DB: db, Logger: logger}
+	c.UsersModule = users.NewUsersModule(db, logger)
+	return c
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	c.UsersModule.RegisterRoutes(api)
+}
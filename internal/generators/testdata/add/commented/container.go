@@ -0,0 +1,45 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/payments"
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+
+	// UsersModule owns the accounts
+	UsersModule *users.UsersModule
+	// PaymentsModule charges the cards
+	PaymentsModule *payments.PaymentsModule // external provider
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	// Modules are built in dependency order, so a module can receive the
+	// modules it depends on.
+	usersModule := users.NewUsersModule(db, logger)
+	// Payments are made by users
+	paymentsModule := payments.NewPaymentsModule(db, logger, usersModule)
+
+	return &Container{
+		DB:     db,
+		Logger: logger,
+
+		// Modules
+		UsersModule:    usersModule,
+		PaymentsModule: paymentsModule, // charged last
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	// Public routes
+	c.UsersModule.RegisterRoutes(api)
+	// Card routes
+	c.PaymentsModule.RegisterRoutes(api)
+}
@@ -0,0 +1,30 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB          *sql.DB
+	Logger      *slog.Logger
+	UsersModule *users.UsersModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	usersModule := users.NewUsersModule(db, logger)
+
+	return &Container{
+		DB:          db,
+		Logger:      logger,
+		UsersModule: usersModule,
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	c.UsersModule.RegisterRoutes(api)
+}
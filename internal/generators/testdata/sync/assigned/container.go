@@ -0,0 +1,30 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/orders"
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB           *sql.DB
+	Logger       *slog.Logger
+	OrdersModule *orders.OrdersModule
+	UsersModule  *users.UsersModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	c := &Container{DB: db, Logger: logger}
+	c.OrdersModule = orders.NewOrdersModule(db, logger, c.UsersModule)
+	c.UsersModule = users.NewUsersModule(db, logger)
+	return c
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	c.OrdersModule.RegisterRoutes(api)
+	c.UsersModule.RegisterRoutes(api)
+}
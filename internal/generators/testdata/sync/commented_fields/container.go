@@ -0,0 +1,42 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/orders"
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+
+	// OrdersModule takes and lists the orders
+	OrdersModule *orders.OrdersModule // needs users
+	// UsersModule owns the accounts
+	UsersModule *users.UsersModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	// Modules are built in dependency order, so a module can receive the
+	// modules it depends on.
+	ordersModule := orders.NewOrdersModule(db, logger, usersModule)
+	// usersModule has no dependencies
+	usersModule := users.NewUsersModule(db, logger) // built first
+
+	return &Container{
+		DB:           db,
+		Logger:       logger,
+		OrdersModule: ordersModule,
+		UsersModule:  usersModule,
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	// Public routes
+	c.OrdersModule.RegisterRoutes(api)
+	c.UsersModule.RegisterRoutes(api)
+}
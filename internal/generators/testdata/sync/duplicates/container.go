@@ -0,0 +1,37 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/orders"
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB           *sql.DB
+	Logger       *slog.Logger
+	UsersModule  *users.UsersModule
+	OrdersModule *orders.OrdersModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	usersModule := users.NewUsersModule(db, logger)
+	ordersModule := orders.NewOrdersModule(db, logger, usersModule)
+	usersModule = users.NewUsersModule(db, logger)
+
+	return &Container{
+		DB:           db,
+		Logger:       logger,
+		UsersModule:  usersModule,
+		OrdersModule: ordersModule,
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	c.UsersModule.RegisterRoutes(api)
+	c.OrdersModule.RegisterRoutes(api)
+	c.UsersModule.RegisterRoutes(api)
+}
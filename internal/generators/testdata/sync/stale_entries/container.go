@@ -0,0 +1,56 @@
+package container
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"example.com/shop/internal/modules/payments"
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB             *sql.DB
+	Logger         *slog.Logger
+	UsersModule    *users.UsersModule
+	PaymentsModule *payments.PaymentsModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	usersModule := users.NewUsersModule(db, logger)
+	paymentsModule := payments.NewPaymentsModule(db, logger)
+
+	return &Container{
+		DB:             db,
+		Logger:         logger,
+		UsersModule:    usersModule,
+		PaymentsModule: paymentsModule,
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	c.UsersModule.RegisterRoutes(api)
+	c.PaymentsModule.RegisterRoutes(api)
+	// Removed along with its field in a merge
+	c.InvoicesModule.RegisterRoutes(api)
+}
+
+func (c *Container) Close(ctx context.Context) error {
+	return closeModules(ctx,
+		c.PaymentsModule,
+		c.UsersModule,
+	)
+}
+
+func closeModules(ctx context.Context, modules ...any) error {
+	var errs []error
+	for _, module := range modules {
+		if m, ok := module.(interface{ Close() error }); ok {
+			errs = append(errs, m.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package container
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"example.com/shop/internal/modules/orders"
+	"example.com/shop/internal/modules/users"
+	"github.com/gin-gonic/gin"
+)
+
+type Container struct {
+	DB           *sql.DB
+	Logger       *slog.Logger
+	OrdersModule *orders.OrdersModule
+	UsersModule  *users.UsersModule
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	ordersModule := orders.NewOrdersModule(db, logger, usersModule)
+	usersModule := users.NewUsersModule(db, logger)
+
+	return &Container{
+		DB:           db,
+		Logger:       logger,
+		OrdersModule: ordersModule,
+		UsersModule:  usersModule,
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	c.OrdersModule.RegisterRoutes(api)
+	c.UsersModule.RegisterRoutes(api)
+}
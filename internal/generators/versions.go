@@ -0,0 +1,179 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	goversion "go/version"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/project"
+)
+
+// requirement is a module a generated project requires.
+type requirement struct {
+	path    string
+	version string
+}
+
+// dependencyVersions pins the modules the generated code imports, by the
+// feature that brings them in. Versions of a feature are known to work
+// together and with those of the others; bump them here, not in templates.
+var dependencyVersions = map[string][]requirement{
+	"http": {
+		{"github.com/gin-gonic/gin", "v1.10.0"},
+	},
+	"postgres": {
+		{"github.com/lib/pq", "v1.10.9"},
+	},
+	"config": {
+		{"github.com/joho/godotenv", "v1.5.1"},
+		{"gopkg.in/yaml.v3", "v3.0.1"},
+	},
+	// The OpenTelemetry exporters are released on their own schedule
+	"observability": {
+		{"github.com/prometheus/client_golang", "v1.23.2"},
+		{"go.opentelemetry.io/otel", "v1.38.0"},
+		{"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp", "v1.38.0"},
+		{"go.opentelemetry.io/otel/exporters/prometheus", "v0.60.0"},
+		{"go.opentelemetry.io/otel/exporters/stdout/stdouttrace", "v1.38.0"},
+		{"go.opentelemetry.io/otel/metric", "v1.38.0"},
+		{"go.opentelemetry.io/otel/sdk", "v1.38.0"},
+		{"go.opentelemetry.io/otel/sdk/metric", "v1.38.0"},
+		{"go.opentelemetry.io/otel/trace", "v1.38.0"},
+	},
+	"auth": {
+		{"github.com/golang-jwt/jwt/v5", "v5.3.1"},
+		{"golang.org/x/crypto", "v0.41.0"},
+	},
+	"grpc": {
+		{"google.golang.org/grpc", "v1.75.0"},
+		{"google.golang.org/protobuf", "v1.36.8"},
+	},
+	"graphql": {
+		{"github.com/graphql-go/graphql", "v0.8.1"},
+	},
+}
+
+// requirements returns the modules required by the given features, in
+// order.
+func requirements(features ...string) []requirement {
+	var reqs []requirement
+	for _, feature := range features {
+		reqs = append(reqs, dependencyVersions[feature]...)
+	}
+	return reqs
+}
+
+// requireFeature adds the modules of a feature to the go.mod of the project
+// in the working directory, leaving those the project already imports at
+// their version; indirect requirements, which other modules chose, are set
+// to the feature's. go mod edit works offline; go mod tidy then fetches them.
+func requireFeature(feature string) error {
+	out, err := exec.Command("go", "mod", "edit", "-json").Output()
+	if err != nil {
+		warn("could not read go.mod to pin the %s dependencies: %v", feature, err)
+		return nil
+	}
+	var goMod struct {
+		Require []struct {
+			Path     string
+			Indirect bool
+		}
+	}
+	if err := json.Unmarshal(out, &goMod); err != nil {
+		return fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	required := make(map[string]bool)
+	for _, r := range goMod.Require {
+		required[r.Path] = !r.Indirect
+	}
+
+	args := []string{"mod", "edit"}
+	for _, r := range requirements(feature) {
+		if !required[r.path] {
+			args = append(args, "-require="+r.path+"@"+r.version)
+		}
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	if out, err := exec.Command("go", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pin the %s dependencies: %w\n%s", feature, err, out)
+	}
+	recordFile("go.mod", false)
+	return nil
+}
+
+// MinimumGoVersion is the oldest go directive of projects, which the
+// pinned dependencies and the generated code (log/slog) need. It is also the
+// directive when the local toolchain cannot tell its version.
+const MinimumGoVersion = "1.21"
+
+// featureGoVersions is the oldest go directive the pinned modules of a
+// feature build with, for the features that need a newer one than
+// MinimumGoVersion. It is spelled as the modules declare it: go mod tidy
+// raises a go 1.23 directive to 1.23.0.
+var featureGoVersions = map[string]string{
+	"observability": "1.23.0",
+	"auth":          "1.23.0",
+	"grpc":          "1.23.0",
+}
+
+// MinimumGoVersionFor returns the oldest go directive of a project with the
+// given features.
+func MinimumGoVersionFor(features ...string) string {
+	minimum := MinimumGoVersion
+	for _, feature := range features {
+		if version, ok := featureGoVersions[feature]; ok && goversion.Compare("go"+version, "go"+minimum) > 0 {
+			minimum = version
+		}
+	}
+	return minimum
+}
+
+// DefaultGoVersion returns the go directive of a new project with the given
+// features: the local toolchain's, or the oldest the features need when the
+// toolchain is older still.
+func DefaultGoVersion(features ...string) string {
+	version, minimum := LocalGoVersion(), MinimumGoVersionFor(features...)
+	if goversion.Compare("go"+version, "go"+minimum) < 0 {
+		return minimum
+	}
+	return version
+}
+
+// checkGoVersion fails when the go directive of the project in the working
+// directory is older than feature needs, before anything is written for it.
+func checkGoVersion(feature string) error {
+	version, minimum := project.GoVersion("go.mod"), MinimumGoVersionFor(feature)
+	if version == "" || goversion.Compare("go"+version, "go"+minimum) >= 0 {
+		return nil
+	}
+	return fmt.Errorf("the %s dependencies need go %s or later and go.mod has go %s; raise it with `go mod edit -go=%s`, and the golang image of the Dockerfile and CI with it", feature, minimum, version, minimum)
+}
+
+var goVersionPattern = regexp.MustCompile(`^go(1\.\d+(?:\.\d+)?)`)
+
+// LocalGoVersion returns the version of the local Go toolchain, such as
+// 1.25.3, for the go directive of new projects.
+func LocalGoVersion() string {
+	version := runtime.Version()
+	if out, err := exec.Command("go", "env", "GOVERSION").Output(); err == nil {
+		version = strings.TrimSpace(string(out))
+	}
+	if m := goVersionPattern.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+	return MinimumGoVersion
+}
+
+// ValidGoVersion reports whether version can be the go directive of a
+// project: 1.N or 1.N.P, no older than MinimumGoVersion.
+func ValidGoVersion(version string) bool {
+	m := goVersionPattern.FindStringSubmatch("go" + version)
+	return m != nil && m[1] == version && goversion.Compare("go"+version, "go"+MinimumGoVersion) >= 0
+}
@@ -0,0 +1,295 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	projecttemplates "github.com/pierslabs/gozilla-cli/internal/templates/project"
+)
+
+var (
+	queuePath       = filepath.Join("internal", "domain", "queue", "queue.go")
+	memoryQueuePath = filepath.Join("internal", "infrastructure", "queue", "memory", "memory.go")
+	workerGroupPath = filepath.Join("internal", "infrastructure", "worker", "worker.go")
+)
+
+type WorkerGenerator struct{}
+
+func NewWorkerGenerator() *WorkerGenerator {
+	return &WorkerGenerator{}
+}
+
+// Generate creates internal/workers/<name> and registers the worker in the
+// container, setting up the job queue and worker lifecycle the first time.
+func (g *WorkerGenerator) Generate(name string) error {
+	if !token.IsIdentifier(name) || strings.ToLower(name) != name {
+		return fmt.Errorf("worker name '%s' must be a lowercase Go identifier, like email", name)
+	}
+
+	workerDir := filepath.Join("internal", "workers", name)
+	if _, err := os.Stat(workerDir); err == nil {
+		return fmt.Errorf("worker '%s' already exists", name)
+	}
+
+	data, err := projectData()
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		queuePath:       projecttemplates.QueueTemplate(data),
+		memoryQueuePath: projecttemplates.MemoryQueueTemplate(data),
+		workerGroupPath: projecttemplates.WorkerRunnerTemplate(data),
+	}
+	for path, content := range files {
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(path, []byte(content)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(workerDir, 0755); err != nil {
+		return err
+	}
+	workerPath := filepath.Join(workerDir, "worker.go")
+	if err := writeFile(workerPath, []byte(projecttemplates.WorkerTemplate(data, name))); err != nil {
+		return fmt.Errorf("failed to write %s: %w", workerPath, err)
+	}
+
+	if err := registerWorker(data.ModulePath, name); err != nil {
+		return fmt.Errorf("failed to update container: %w", err)
+	}
+
+	if err := startWorkers(data.ModulePath + "/internal/infrastructure/worker"); err != nil {
+		return fmt.Errorf("failed to update main.go: %w", err)
+	}
+
+	return nil
+}
+
+// registerWorker builds the worker in NewContainer and lists it in
+// Container.Workers. The first worker also gets the container a job queue,
+// Jobs, which every worker consumes. Workers log with the container's logger.
+func registerWorker(modulePath, name string) error {
+	src, err := os.ReadFile(containerPath)
+	if err != nil {
+		return fmt.Errorf("failed to read container.go: %w", err)
+	}
+
+	cs, err := parseContainer(src)
+	if err != nil {
+		return err
+	}
+
+	err = cs.addSharedValue(sharedValue{
+		field:      "Jobs",
+		fieldType:  "*memory.Queue",
+		variable:   "jobs",
+		init:       "memory.NewQueue(100)",
+		comment:    "Jobs for the background workers",
+		importPath: modulePath + "/internal/infrastructure/queue/memory",
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cs.addLogger(); err != nil {
+		return err
+	}
+
+	fieldName := strings.Title(name) + "Worker"
+	if err := cs.addImport(modulePath + "/internal/workers/" + name); err != nil {
+		return err
+	}
+	if err := cs.addWorker(fieldName, name); err != nil {
+		return err
+	}
+
+	if err := cs.addImport(modulePath + "/internal/infrastructure/worker"); err != nil {
+		return err
+	}
+
+	fn := cs.method("Workers")
+	if fn == nil {
+		recv := "c"
+		if routes := cs.method("RegisterRoutes"); routes != nil && len(routes.Recv.List[0].Names) > 0 {
+			recv = routes.Recv.List[0].Names[0].Name
+		}
+		err = cs.apply(edit{len(cs.src), len(cs.src), projecttemplates.WorkersMethod(recv, fieldName)})
+	} else {
+		err = cs.addWorkerEntry(fn, fieldName)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeFile(containerPath, cs.src)
+}
+
+// addWorker adds the worker's field and its construction in the Container
+// literal, below the workers already there.
+func (cs *containerSource) addWorker(fieldName, name string) error {
+	structType := cs.containerStruct()
+	if structType == nil {
+		return fmt.Errorf("%s: cannot find the Container struct; declare `type Container struct { ... }`", containerPath)
+	}
+
+	var lastField *ast.Field
+	for _, field := range structType.Fields.List {
+		for _, ident := range field.Names {
+			if ident.Name == fieldName {
+				return nil
+			}
+		}
+		if star, ok := field.Type.(*ast.StarExpr); ok {
+			if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "Worker" {
+				lastField = field
+			}
+		}
+	}
+	if lastField == nil {
+		if err := cs.addField(fieldName, "*"+name+".Worker"); err != nil {
+			return err
+		}
+		return cs.addLiteralEntry(fieldName, name+".NewWorker(jobs, logger)")
+	}
+
+	if err := cs.insertAfter(lastField, structType.Fields.Closing, "\t"+fieldName+" *"+name+".Worker"); err != nil {
+		return err
+	}
+
+	b, err := cs.builder()
+	if err != nil {
+		return err
+	}
+	var lastEntry ast.Expr
+	for _, elt := range b.lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if call, ok := kv.Value.(*ast.CallExpr); ok {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewWorker" {
+					lastEntry = elt
+				}
+			}
+		}
+	}
+	if lastEntry == nil || cs.line(lastEntry.End()) == cs.line(b.lit.Rbrace) {
+		return cs.addLiteralEntry(fieldName, name+".NewWorker(jobs, logger)")
+	}
+	return cs.insertAfter(lastEntry, b.lit.Rbrace, "\t"+fieldName+": "+name+".NewWorker(jobs, logger),")
+}
+
+// addWorkerEntry adds the worker to the slice Container.Workers returns.
+func (cs *containerSource) addWorkerEntry(fn *ast.FuncDecl, fieldName string) error {
+	if len(fn.Recv.List[0].Names) == 0 {
+		return cs.errorf(fn, "Workers has an unnamed receiver; name it to let gozilla register workers")
+	}
+	recv := fn.Recv.List[0].Names[0].Name
+
+	var lit *ast.CompositeLit
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if ret, ok := n.(*ast.ReturnStmt); ok && lit == nil && len(ret.Results) == 1 {
+			lit, _ = ret.Results[0].(*ast.CompositeLit)
+		}
+		return lit == nil
+	})
+	if lit == nil {
+		return cs.errorf(fn, "cannot find the []worker.Worker{...} that Workers returns; add %s.%s by hand", recv, fieldName)
+	}
+
+	entry := recv + "." + fieldName
+	for _, elt := range lit.Elts {
+		if cs.text(elt) == entry {
+			return nil
+		}
+	}
+
+	switch {
+	case len(lit.Elts) == 0:
+		off := cs.offset(lit.Rbrace)
+		return cs.apply(edit{off, off, "\n" + entry + ",\n"})
+	case cs.line(lit.Elts[len(lit.Elts)-1].End()) == cs.line(lit.Rbrace):
+		off := cs.offset(lit.Rbrace)
+		return cs.apply(edit{off, off, ", " + entry})
+	default:
+		return cs.insertAfter(lit.Elts[len(lit.Elts)-1], lit.Rbrace, "\t"+entry+",")
+	}
+}
+
+// startWorkers makes main.go run the container's workers before starting
+// the HTTP server, and wait for them to stop on SIGINT/SIGTERM.
+func startWorkers(importPath string) error {
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		return fmt.Errorf("failed to read main.go: %w", err)
+	}
+
+	cs, err := parseSource(mainPath, src)
+	if err != nil {
+		return err
+	}
+
+	for _, imp := range cs.file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+			return nil
+		}
+	}
+
+	fn := cs.function("main")
+	if fn == nil {
+		return fmt.Errorf("%s: cannot find func main; run worker.Start(ctx, container.Workers()...) by hand", mainPath)
+	}
+
+	httpStart := cs.httpServerStart(fn)
+	if httpStart == nil {
+		return cs.errorf(fn, "cannot find where the HTTP server is started; run worker.Start(ctx, container.Workers()...) by hand")
+	}
+
+	start := httpStart.Pos()
+	if doc := cs.leadingComment(httpStart); doc != nil {
+		start = doc.Pos()
+	}
+	off := cs.lineStart(start)
+
+	// Projects that shut down gracefully stop the workers with the servers;
+	// older ones stop them on their own.
+	ctx := cs.signalContext(fn)
+	shutdown, _ := cs.httpServerShutdown(fn)
+	if ctx == "" || shutdown == nil {
+		if err := cs.apply(edit{off, off, projecttemplates.WorkersMainBlock()}); err != nil {
+			return err
+		}
+		for _, p := range []string{"context", "os", "os/signal", "syscall"} {
+			if err := cs.addImport(p); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := cs.apply(edit{off, off, projecttemplates.WorkersStartBlock(ctx)}); err != nil {
+			return err
+		}
+		fn = cs.function("main")
+		shutdown, shutdownCtx := cs.httpServerShutdown(fn)
+		if err := cs.insertAfter(shutdown, fn.Body.Rbrace, projecttemplates.WorkersWaitBlock(shutdownCtx)); err != nil {
+			return err
+		}
+		if err := cs.addImport("log/slog"); err != nil {
+			return err
+		}
+	}
+
+	if err := cs.addImport(importPath); err != nil {
+		return err
+	}
+
+	return writeFile(mainPath, cs.src)
+}
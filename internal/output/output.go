@@ -0,0 +1,226 @@
+// Package output prints what gozilla commands did: as text for people, or
+// with --output json as one JSON object for scripts and editor extensions.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pierslabs/gozilla-cli/internal/generators"
+	"github.com/spf13/cobra"
+)
+
+// Set from the global flags.
+var (
+	JSON    bool // print a Result as JSON instead of text
+	Quiet   bool // print only the command's output, warnings and errors
+	NoColor bool // print text without colors or emoji
+)
+
+// Result is what a command did, printed with --output json.
+type Result struct {
+	Command   string   `json:"command"`
+	Success   bool     `json:"success"`
+	Message   string   `json:"message,omitempty"`
+	Created   []string `json:"created"`
+	Modified  []string `json:"modified"`
+	Warnings  []string `json:"warnings"`
+	NextSteps []string `json:"next_steps"`
+	Data      any      `json:"data,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Reporter reports the progress and result of a command.
+type Reporter struct {
+	result   Result
+	sections []section
+}
+
+type section struct {
+	title string
+	lines []string
+}
+
+// current is the reporter of the running command, so that Fail can report
+// what it did before the error.
+var current *Reporter
+
+// Start returns the reporter of a running command.
+func Start(cmd *cobra.Command) *Reporter {
+	current = &Reporter{result: Result{Command: commandName(cmd)}}
+	return current
+}
+
+// commandName is the path of cmd below the root, such as "generate module".
+func commandName(cmd *cobra.Command) string {
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+	return strings.TrimSpace(name)
+}
+
+// Step prints a progress message.
+func (r *Reporter) Step(icon, format string, args ...any) {
+	if JSON || Quiet {
+		return
+	}
+	fmt.Println(WithIcon(icon, fmt.Sprintf(format, args...)))
+}
+
+// Print prints output of the command itself, such as a diagram.
+func (r *Reporter) Print(text string) {
+	if JSON {
+		return
+	}
+	fmt.Print(text)
+}
+
+// Problem prints something wrong that the command found.
+func (r *Reporter) Problem(format string, args ...any) {
+	if JSON {
+		return
+	}
+	fmt.Fprintln(os.Stderr, paint(os.Stderr, red, WithIcon("❌", fmt.Sprintf(format, args...))))
+}
+
+// Warn records a warning, printed as it happens.
+func (r *Reporter) Warn(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	r.result.Warnings = append(r.result.Warnings, message)
+	if !JSON {
+		fmt.Fprintln(os.Stderr, paint(os.Stderr, yellow, WithIcon("⚠️ ", message)))
+	}
+}
+
+// Section adds a titled list printed after the files, such as the
+// endpoints a command added.
+func (r *Reporter) Section(title string, lines ...string) {
+	r.sections = append(r.sections, section{title, lines})
+}
+
+// Next adds steps for the user to take after the command.
+func (r *Reporter) Next(steps ...string) {
+	r.result.NextSteps = append(r.result.NextSteps, steps...)
+}
+
+// Data sets the structured output of the command, printed with --output
+// json in place of its text.
+func (r *Reporter) Data(v any) {
+	r.result.Data = v
+}
+
+// Done prints the result of a successful command: the message, the files
+// the generators wrote, the sections and the next steps.
+func (r *Reporter) Done(format string, args ...any) error {
+	r.collect()
+	r.result.Success = true
+	r.result.Message = fmt.Sprintf(format, args...)
+	if JSON {
+		return printJSON(r.result)
+	}
+	if Quiet {
+		return nil
+	}
+
+	if r.result.Message != "" {
+		fmt.Printf("\n%s\n\n", paint(os.Stdout, green, WithIcon("✅", r.result.Message)))
+	}
+	printList("Created:", r.result.Created)
+	printList("Modified:", r.result.Modified)
+	for _, s := range r.sections {
+		printList(s.title, s.lines)
+	}
+	if len(r.result.NextSteps) > 0 {
+		fmt.Printf("Next steps:\n")
+		for i, step := range r.result.NextSteps {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+	return nil
+}
+
+// Fail prints the error cmd failed with. With --output json it is the
+// command's Result, including the files written before the error.
+func Fail(cmd *cobra.Command, err error) {
+	r := current
+	if r == nil {
+		r = Start(cmd)
+	}
+	r.collect()
+	r.result.Error = err.Error()
+
+	if JSON {
+		printJSON(r.result)
+		return
+	}
+	fmt.Fprintln(os.Stderr, paint(os.Stderr, red, "Error: "+err.Error()))
+}
+
+// collect takes the files written and the warnings recorded by the
+// generators, sorted by path.
+func (r *Reporter) collect() {
+	changes := generators.TakeChanges()
+	r.result.Created = append(r.result.Created, changes.Created...)
+	r.result.Modified = append(r.result.Modified, changes.Modified...)
+	slices.Sort(r.result.Created)
+	slices.Sort(r.result.Modified)
+	for _, warning := range changes.Warnings {
+		r.Warn("%s", warning)
+	}
+}
+
+func printJSON(result Result) error {
+	// Scripts get lists, never null
+	for _, list := range []*[]string{&result.Created, &result.Modified, &result.Warnings, &result.NextSteps} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+func printList(title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Println(title)
+	for _, line := range lines {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println()
+}
+
+// WithIcon prefixes message with an emoji, unless --no-color is set.
+func WithIcon(icon, message string) string {
+	if NoColor || icon == "" {
+		return message
+	}
+	return icon + " " + message
+}
+
+const (
+	red    = "31"
+	green  = "32"
+	yellow = "33"
+)
+
+// paint colors text written to w, unless w is not a terminal or colors are
+// turned off.
+func paint(w io.Writer, color, text string) string {
+	f, ok := w.(*os.File)
+	if NoColor || !ok || !IsTerminal(f) {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
+
+// IsTerminal reports whether f is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
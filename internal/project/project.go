@@ -0,0 +1,89 @@
+// Package project finds the root of the gozilla project a command runs in
+// and reads its go.mod.
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// ConfigFile marks projects adopted with `gozilla init`.
+const ConfigFile = ".gozilla.yaml"
+
+// FindRoot returns the root of the project dir is in. Walking up from dir,
+// the root is the first directory holding .gozilla.yaml, or a go.mod next
+// to internal/modules; nested modules, such as tools/go.mod, are skipped
+// that way. Projects without modules yet fall back to the nearest go.mod.
+func FindRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	nearest := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if exists(filepath.Join(d, ConfigFile)) {
+			return d, nil
+		}
+		if exists(filepath.Join(d, "go.mod")) {
+			if exists(filepath.Join(d, "internal", "modules")) {
+				return d, nil
+			}
+			if nearest == "" {
+				nearest = d
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if nearest == "" {
+		return "", fmt.Errorf("not in a Go project (go.mod not found in %s or its parents)", dir)
+	}
+	return nearest, nil
+}
+
+// ModulePath returns the module directive of the given go.mod file.
+func ModulePath(goModPath string) (string, error) {
+	f, err := parse(goModPath)
+	if err != nil {
+		return "", err
+	}
+	if f.Module == nil || f.Module.Mod.Path == "" {
+		return "", fmt.Errorf("%s has no module directive", goModPath)
+	}
+	return f.Module.Mod.Path, nil
+}
+
+// GoVersion returns the go directive of the given go.mod file, or "" when
+// it has none or cannot be read.
+func GoVersion(goModPath string) string {
+	f, err := parse(goModPath)
+	if err != nil || f.Go == nil {
+		return ""
+	}
+	return f.Go.Version
+}
+
+func parse(goModPath string) (*modfile.File, error) {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+	// Lax parsing reads only the directives gozilla needs, so a go.mod
+	// written by a newer toolchain still parses
+	f, err := modfile.ParseLax(goModPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", goModPath, err)
+	}
+	return f, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
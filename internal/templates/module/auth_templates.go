@@ -0,0 +1,531 @@
+package templates
+
+import "fmt"
+
+// The templates below make up the auth module of `gozilla add auth --jwt`:
+// register, login and refresh endpoints over an auth_users table, with
+// bcrypt password hashing and tokens from the project's security.TokenIssuer.
+
+func AuthModuleTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package auth
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"%s/internal/domain/security"
+	"%s/internal/infrastructure/http/middleware"
+	"%s/auth/application/usecases"
+	"%s/auth/infra"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthModule struct {
+	Handler     *infra.AuthHandler
+	requireAuth gin.HandlerFunc
+}
+
+func NewAuthModule(db *sql.DB, logger *slog.Logger, tokens security.TokenIssuer, verifier security.TokenVerifier) *AuthModule {
+	logger = logger.With("module", "auth")
+	repo := infra.NewUserRepository(db, logger)
+	hasher := infra.NewBcryptHasher()
+
+	registerUC := usecases.NewRegisterUseCase(repo, hasher, tokens, logger)
+	loginUC := usecases.NewLoginUseCase(repo, hasher, tokens, logger)
+	refreshUC := usecases.NewRefreshUseCase(repo, tokens, logger)
+
+	handler := infra.NewAuthHandler(registerUC, loginUC, refreshUC)
+
+	return &AuthModule{
+		Handler:     handler,
+		requireAuth: middleware.RequireAuth(verifier),
+	}
+}
+
+func (m *AuthModule) RegisterRoutes(r *gin.RouterGroup) {
+	infra.RegisterRoutes(r, m.Handler, m.requireAuth)
+}
+`, data.ModulePath, data.ModulePath, data.ModulesImportPath, data.ModulesImportPath)
+}
+
+func AuthUserTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package domain
+
+import (
+	"time"
+
+	"%s/internal/domain/security"
+)
+
+type User struct {
+	ID           int64     `+"`json:\"id\"`"+`
+	Email        string    `+"`json:\"email\"`"+`
+	PasswordHash string    `+"`json:\"-\"`"+`
+	Roles        []string  `+"`json:\"roles\"`"+`
+	CreatedAt    time.Time `+"`json:\"created_at\"`"+`
+	UpdatedAt    time.Time `+"`json:\"updated_at\"`"+`
+}
+
+// Principal is who the user acts as once authenticated.
+func (u *User) Principal() security.Principal {
+	return security.Principal{UserID: u.ID, Email: u.Email, Roles: u.Roles}
+}
+`, data.ModulePath)
+}
+
+func AuthDomainTemplate(data ModuleData) string {
+	return `package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id int64) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+}
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hash, password string) error
+}
+`
+}
+
+func AuthDTOTemplate(data ModuleData) string {
+	return `package dto
+
+type RegisterDTO struct {
+	Email    string ` + "`json:\"email\" binding:\"required,email\"`" + `
+	Password string ` + "`json:\"password\" binding:\"required,min=8\"`" + `
+}
+
+type LoginDTO struct {
+	Email    string ` + "`json:\"email\" binding:\"required\"`" + `
+	Password string ` + "`json:\"password\" binding:\"required\"`" + `
+}
+
+type RefreshDTO struct {
+	RefreshToken string ` + "`json:\"refresh_token\" binding:\"required\"`" + `
+}
+`
+}
+
+func RegisterUseCaseTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"%s/internal/domain/security"
+	"%s/auth/application/dto"
+	"%s/auth/domain"
+)
+
+// DefaultRoles are given to every user that registers.
+var DefaultRoles = []string{"user"}
+
+type RegisterUseCase struct {
+	repo   domain.UserRepository
+	hasher domain.PasswordHasher
+	tokens security.TokenIssuer
+	logger *slog.Logger
+}
+
+func NewRegisterUseCase(repo domain.UserRepository, hasher domain.PasswordHasher, tokens security.TokenIssuer, logger *slog.Logger) *RegisterUseCase {
+	return &RegisterUseCase{
+		repo:   repo,
+		hasher: hasher,
+		tokens: tokens,
+		logger: logger,
+	}
+}
+
+func (uc *RegisterUseCase) Execute(ctx context.Context, input dto.RegisterDTO) (security.TokenPair, error) {
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+
+	if _, err := uc.repo.GetByEmail(ctx, email); err == nil {
+		return security.TokenPair{}, domain.ErrEmailTaken
+	} else if !errors.Is(err, domain.ErrUserNotFound) {
+		return security.TokenPair{}, err
+	}
+
+	hash, err := uc.hasher.Hash(input.Password)
+	if err != nil {
+		return security.TokenPair{}, err
+	}
+
+	user := &domain.User{
+		Email:        email,
+		PasswordHash: hash,
+		Roles:        DefaultRoles,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := uc.repo.Create(ctx, user); err != nil {
+		return security.TokenPair{}, err
+	}
+
+	uc.logger.InfoContext(ctx, "user registered", "user_id", user.ID)
+	return uc.tokens.Issue(user.Principal())
+}
+`, data.ModulePath, data.ModulesImportPath, data.ModulesImportPath)
+}
+
+func LoginUseCaseTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"%s/internal/domain/security"
+	"%s/auth/application/dto"
+	"%s/auth/domain"
+)
+
+type LoginUseCase struct {
+	repo      domain.UserRepository
+	hasher    domain.PasswordHasher
+	tokens    security.TokenIssuer
+	logger    *slog.Logger
+	dummyHash string
+}
+
+func NewLoginUseCase(repo domain.UserRepository, hasher domain.PasswordHasher, tokens security.TokenIssuer, logger *slog.Logger) *LoginUseCase {
+	// Hashed once, as the hasher would hash a real password, for logins with
+	// an unknown email to cost as much as those with a wrong password.
+	dummyHash, err := hasher.Hash("gozilla-dummy-password")
+	if err != nil {
+		logger.Error("failed to hash the dummy password", "error", err)
+	}
+
+	return &LoginUseCase{
+		repo:      repo,
+		hasher:    hasher,
+		tokens:    tokens,
+		logger:    logger,
+		dummyHash: dummyHash,
+	}
+}
+
+func (uc *LoginUseCase) Execute(ctx context.Context, input dto.LoginDTO) (security.TokenPair, error) {
+	user, err := uc.repo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(input.Email)))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		// Compare anyway, so the response time does not tell which emails
+		// have an account.
+		uc.hasher.Compare(uc.dummyHash, input.Password)
+		return security.TokenPair{}, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return security.TokenPair{}, err
+	}
+
+	if err := uc.hasher.Compare(user.PasswordHash, input.Password); err != nil {
+		uc.logger.WarnContext(ctx, "login failed: wrong password", "user_id", user.ID)
+		return security.TokenPair{}, domain.ErrInvalidCredentials
+	}
+
+	uc.logger.InfoContext(ctx, "user logged in", "user_id", user.ID)
+	return uc.tokens.Issue(user.Principal())
+}
+`, data.ModulePath, data.ModulesImportPath, data.ModulesImportPath)
+}
+
+func RefreshUseCaseTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"%s/internal/domain/security"
+	"%s/auth/application/dto"
+	"%s/auth/domain"
+)
+
+type RefreshUseCase struct {
+	repo   domain.UserRepository
+	tokens security.TokenIssuer
+	logger *slog.Logger
+}
+
+func NewRefreshUseCase(repo domain.UserRepository, tokens security.TokenIssuer, logger *slog.Logger) *RefreshUseCase {
+	return &RefreshUseCase{
+		repo:   repo,
+		tokens: tokens,
+		logger: logger,
+	}
+}
+
+// Execute issues new tokens for a refresh token. The user is loaded again,
+// so that role changes and removed users take effect.
+func (uc *RefreshUseCase) Execute(ctx context.Context, input dto.RefreshDTO) (security.TokenPair, error) {
+	principal, err := uc.tokens.VerifyRefresh(input.RefreshToken)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "refresh rejected", "error", err)
+		return security.TokenPair{}, err
+	}
+
+	user, err := uc.repo.GetByID(ctx, principal.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return security.TokenPair{}, security.ErrInvalidToken
+	}
+	if err != nil {
+		return security.TokenPair{}, err
+	}
+
+	return uc.tokens.Issue(user.Principal())
+}
+`, data.ModulePath, data.ModulesImportPath, data.ModulesImportPath)
+}
+
+func AuthRepositoryTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"%s/auth/domain"
+	"github.com/lib/pq"
+)
+
+type UserRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewUserRepository(db *sql.DB, logger *slog.Logger) *UserRepository {
+	return &UserRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `+"`"+`
+		INSERT INTO auth_users (email, password_hash, roles, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`+"`"+`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		user.Email,
+		user.PasswordHash,
+		pq.Array(user.Roles),
+		user.CreatedAt,
+		user.UpdatedAt,
+	).Scan(&user.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return domain.ErrEmailTaken
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "insert failed", "error", err)
+	}
+
+	return err
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return r.get(ctx, "id = $1", id)
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.get(ctx, "email = $1", email)
+}
+
+func (r *UserRepository) get(ctx context.Context, where string, arg any) (*domain.User, error) {
+	query := `+"`"+`
+		SELECT id, email, password_hash, roles, created_at, updated_at
+		FROM auth_users
+		WHERE `+"`"+` + where
+
+	user := &domain.User{}
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		pq.Array(&user.Roles),
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "select failed", "error", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+`, data.ModulesImportPath)
+}
+
+func BcryptHasherTemplate(data ModuleData) string {
+	return `package infra
+
+import "golang.org/x/crypto/bcrypt"
+
+type BcryptHasher struct{}
+
+func NewBcryptHasher() *BcryptHasher {
+	return &BcryptHasher{}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func (h *BcryptHasher) Compare(hash, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+`
+}
+
+func AuthHandlerTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package infra
+
+import (
+	"errors"
+	"net/http"
+
+	"%s/internal/domain/security"
+	"%s/auth/application/dto"
+	"%s/auth/application/usecases"
+	"%s/auth/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	registerUC *usecases.RegisterUseCase
+	loginUC    *usecases.LoginUseCase
+	refreshUC  *usecases.RefreshUseCase
+}
+
+func NewAuthHandler(
+	registerUC *usecases.RegisterUseCase,
+	loginUC *usecases.LoginUseCase,
+	refreshUC *usecases.RefreshUseCase,
+) *AuthHandler {
+	return &AuthHandler{
+		registerUC: registerUC,
+		loginUC:    loginUC,
+		refreshUC:  refreshUC,
+	}
+}
+
+func (h *AuthHandler) Register(c *gin.Context) {
+	var input dto.RegisterDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.registerUC.Execute(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(statusOf(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tokens)
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var input dto.LoginDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.loginUC.Execute(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(statusOf(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var input dto.RefreshDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.refreshUC.Execute(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(statusOf(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Me returns the principal of the access token.
+func (h *AuthHandler) Me(c *gin.Context) {
+	principal, ok := security.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	c.JSON(http.StatusOK, principal)
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmailTaken):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, security.ErrInvalidToken):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+`, data.ModulePath, data.ModulesImportPath, data.ModulesImportPath, data.ModulesImportPath)
+}
+
+func AuthRoutesTemplate(data ModuleData) string {
+	return `package infra
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.RouterGroup, handler *AuthHandler, requireAuth gin.HandlerFunc) {
+	auth := r.Group("/auth")
+	{
+		auth.POST("/register", handler.Register)
+		auth.POST("/login", handler.Login)
+		auth.POST("/refresh", handler.Refresh)
+		auth.GET("/me", requireAuth, handler.Me)
+	}
+}
+`
+}
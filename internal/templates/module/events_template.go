@@ -0,0 +1,60 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+func DomainEventsTemplate(data ModuleData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "package domain\n\n// Events published by the %s module.\n", data.ModuleName)
+	for _, name := range []string{"Created", "Updated"} {
+		b.WriteString(EventTemplate(data, data.EntityName+name, "ID int64 `json:\"id\"`\n\tName string `json:\"name\"`"))
+	}
+	b.WriteString(EventTemplate(data, data.EntityName+"Deleted", "ID int64 `json:\"id\"`"))
+	return b.String()
+}
+
+// EventTemplate declares one event type of the module. Its name is prefixed
+// with the module so that modules can't publish the same event name.
+func EventTemplate(data ModuleData, name, fields string) string {
+	return fmt.Sprintf(`
+type %s struct {
+	%s
+}
+
+func (%s) EventName() string { return "%s.%s" }
+`, name, fields, name, data.ModuleName, name)
+}
+
+// SubscriberTemplate handles event, published by the module publisher, in
+// the subscribing module's application layer.
+func SubscriberTemplate(data ModuleData, publisher, event string) string {
+	return fmt.Sprintf(`package subscribers
+
+import (
+	"context"
+
+	"%s/internal/domain/events"
+	%sdomain "%s/%s/domain"
+)
+
+// On%s reacts to %s.%s.
+// Events are delivered at least once, so it should be idempotent.
+func On%s(ctx context.Context, envelope events.Envelope) error {
+	var event %sdomain.%s
+	if err := envelope.Decode(&event); err != nil {
+		return err
+	}
+
+	// React to the event here.
+	_ = event
+
+	return nil
+}
+`, data.ModulePath,
+		publisher, data.ModulesImportPath, publisher,
+		event, publisher, event,
+		event,
+		publisher, event)
+}
@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+func GraphQLSchemaTemplate(data ModuleData) string {
+	entityVar := strings.ToLower(data.EntityName[:1]) + data.EntityName[1:]
+	listField := data.ModuleName
+	if listField == entityVar {
+		listField += "List"
+	}
+	return fmt.Sprintf(`package infra
+
+import "github.com/graphql-go/graphql"
+
+// The %s fragment of the /graphql schema, derived from the domain entity
+// and the DTOs.
+
+var %sType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "%s",
+	Fields: graphql.Fields{
+		"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+	},
+})
+
+var create%sInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "Create%sInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var update%sInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "Update%sInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name": &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var idArgument = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+
+func RegisterGraphQL(queries, mutations graphql.Fields, resolver *%sResolver) {
+	queries["%s"] = &graphql.Field{
+		Type:    %sType,
+		Args:    graphql.FieldConfigArgument{"id": idArgument},
+		Resolve: resolver.Get,
+	}
+	queries["%s"] = &graphql.Field{
+		Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(%sType))),
+		Resolve: resolver.List,
+	}
+
+	mutations["create%s"] = &graphql.Field{
+		Type: graphql.NewNonNull(%sType),
+		Args: graphql.FieldConfigArgument{
+			"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(create%sInput)},
+		},
+		Resolve: resolver.Create,
+	}
+	mutations["update%s"] = &graphql.Field{
+		Type: graphql.NewNonNull(%sType),
+		Args: graphql.FieldConfigArgument{
+			"id":    idArgument,
+			"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(update%sInput)},
+		},
+		Resolve: resolver.Update,
+	}
+	mutations["delete%s"] = &graphql.Field{
+		Type:    graphql.NewNonNull(graphql.Boolean),
+		Args:    graphql.FieldConfigArgument{"id": idArgument},
+		Resolve: resolver.Delete,
+	}
+}
+`, data.ModuleName,
+		entityVar, data.EntityName,
+		data.EntityName, data.EntityName,
+		data.EntityName, data.EntityName,
+		data.ModuleNameTitle,
+		entityVar, entityVar,
+		listField, entityVar,
+		data.EntityName, entityVar, data.EntityName,
+		data.EntityName, entityVar, data.EntityName,
+		data.EntityName)
+}
+
+func GraphQLResolverTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package infra
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"%s/%s/application/dto"
+	"%s/%s/application/usecases"
+	"github.com/graphql-go/graphql"
+)
+
+// %sResolver resolves the %s queries and mutations with the same use cases
+// as the HTTP handler.
+type %sResolver struct {
+	createUC *usecases.Create%sUseCase
+	getUC    *usecases.Get%sUseCase
+	listUC   *usecases.List%sUseCase
+	updateUC *usecases.Update%sUseCase
+	deleteUC *usecases.Delete%sUseCase
+}
+
+func New%sResolver(
+	createUC *usecases.Create%sUseCase,
+	getUC *usecases.Get%sUseCase,
+	listUC *usecases.List%sUseCase,
+	updateUC *usecases.Update%sUseCase,
+	deleteUC *usecases.Delete%sUseCase,
+) *%sResolver {
+	return &%sResolver{
+		createUC: createUC,
+		getUC:    getUC,
+		listUC:   listUC,
+		updateUC: updateUC,
+		deleteUC: deleteUC,
+	}
+}
+
+func (r *%sResolver) Get(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getUC.Execute(p.Context, id)
+}
+
+func (r *%sResolver) List(p graphql.ResolveParams) (interface{}, error) {
+	return r.listUC.Execute(p.Context)
+}
+
+func (r *%sResolver) Create(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	name, _ := input["name"].(string)
+
+	return r.createUC.Execute(p.Context, dto.Create%sDTO{Name: name})
+}
+
+func (r *%sResolver) Update(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+
+	input, _ := p.Args["input"].(map[string]interface{})
+	name, _ := input["name"].(string)
+
+	return r.updateUC.Execute(p.Context, id, dto.Update%sDTO{Name: name})
+}
+
+func (r *%sResolver) Delete(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.deleteUC.Execute(p.Context, id); err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+func parseID(p graphql.ResolveParams) (int64, error) {
+	id, err := strconv.ParseInt(fmt.Sprint(p.Args["id"]), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
+`, data.ModulesImportPath, data.ModuleName, data.ModulesImportPath, data.ModuleName,
+		data.ModuleNameTitle, data.ModuleName,
+		data.ModuleNameTitle,
+		data.EntityName, data.EntityName, data.ModuleNameTitle, data.EntityName, data.EntityName,
+		data.ModuleNameTitle,
+		data.EntityName, data.EntityName, data.ModuleNameTitle, data.EntityName, data.EntityName,
+		data.ModuleNameTitle, data.ModuleNameTitle,
+		data.ModuleNameTitle,
+		data.ModuleNameTitle,
+		data.ModuleNameTitle, data.EntityName,
+		data.ModuleNameTitle, data.EntityName,
+		data.ModuleNameTitle)
+}
@@ -0,0 +1,165 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+func GRPCServerTemplate(data ModuleData) string {
+	entityVar := strings.ToLower(data.EntityName[:1])
+	pb := data.ModuleName + "pb"
+	return fmt.Sprintf(`package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"%s/%s/application/dto"
+	"%s/%s/application/usecases"
+	"%s/%s/domain"
+	%s "%s/%s/infra/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// %sGRPCServer exposes the %s use cases over gRPC. Messages are mapped to
+// the same DTOs the HTTP handler uses.
+type %sGRPCServer struct {
+	%s.Unimplemented%sServiceServer
+
+	createUC *usecases.Create%sUseCase
+	getUC    *usecases.Get%sUseCase
+	listUC   *usecases.List%sUseCase
+	updateUC *usecases.Update%sUseCase
+	deleteUC *usecases.Delete%sUseCase
+}
+
+func New%sGRPCServer(
+	createUC *usecases.Create%sUseCase,
+	getUC *usecases.Get%sUseCase,
+	listUC *usecases.List%sUseCase,
+	updateUC *usecases.Update%sUseCase,
+	deleteUC *usecases.Delete%sUseCase,
+) *%sGRPCServer {
+	return &%sGRPCServer{
+		createUC: createUC,
+		getUC:    getUC,
+		listUC:   listUC,
+		updateUC: updateUC,
+		deleteUC: deleteUC,
+	}
+}
+
+func RegisterGRPCService(s grpc.ServiceRegistrar, server *%sGRPCServer) {
+	%s.Register%sServiceServer(s, server)
+}
+
+func (srv *%sGRPCServer) Create%s(ctx context.Context, req *%s.Create%sRequest) (*%s.%s, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	%s, err := srv.createUC.Execute(ctx, dto.Create%sDTO{Name: req.GetName()})
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return to%sProto(%s), nil
+}
+
+func (srv *%sGRPCServer) Get%s(ctx context.Context, req *%s.Get%sRequest) (*%s.%s, error) {
+	%s, err := srv.getUC.Execute(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return to%sProto(%s), nil
+}
+
+func (srv *%sGRPCServer) List%s(ctx context.Context, req *%s.List%sRequest) (*%s.List%sResponse, error) {
+	%s, err := srv.listUC.Execute(ctx)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	res := &%s.List%sResponse{}
+	for _, %s := range %s {
+		res.%s = append(res.%s, to%sProto(%s))
+	}
+
+	return res, nil
+}
+
+func (srv *%sGRPCServer) Update%s(ctx context.Context, req *%s.Update%sRequest) (*%s.%s, error) {
+	%s, err := srv.updateUC.Execute(ctx, req.GetId(), dto.Update%sDTO{Name: req.GetName()})
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return to%sProto(%s), nil
+}
+
+func (srv *%sGRPCServer) Delete%s(ctx context.Context, req *%s.Delete%sRequest) (*%s.Delete%sResponse, error) {
+	if err := srv.deleteUC.Execute(ctx, req.GetId()); err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return &%s.Delete%sResponse{}, nil
+}
+
+func to%sProto(%s *domain.%s) *%s.%s {
+	return &%s.%s{
+		Id:        %s.ID,
+		Name:      %s.Name,
+		CreatedAt: timestamppb.New(%s.CreatedAt),
+		UpdatedAt: timestamppb.New(%s.UpdatedAt),
+	}
+}
+
+// grpcError maps a use case error to a gRPC status. Only not-found errors
+// reach the client as they are; anything else is logged and reported as
+// Internal without its details.
+func grpcError(ctx context.Context, err error) error {
+	if errors.Is(err, domain.Err%sNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	slog.ErrorContext(ctx, "grpc request failed", "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+`, data.ModulesImportPath, data.ModuleName,
+		data.ModulesImportPath, data.ModuleName,
+		data.ModulesImportPath, data.ModuleName,
+		pb, data.ModulesImportPath, data.ModuleName,
+		data.ModuleNameTitle, data.ModuleName,
+		data.ModuleNameTitle,
+		pb, data.ModuleNameTitle,
+		data.EntityName, data.EntityName, data.ModuleNameTitle, data.EntityName, data.EntityName,
+		data.ModuleNameTitle,
+		data.EntityName, data.EntityName, data.ModuleNameTitle, data.EntityName, data.EntityName,
+		data.ModuleNameTitle, data.ModuleNameTitle,
+		data.ModuleNameTitle,
+		pb, data.ModuleNameTitle,
+		data.ModuleNameTitle, data.EntityName, pb, data.EntityName, pb, data.EntityName,
+		entityVar, data.EntityName,
+		data.EntityName, entityVar,
+		data.ModuleNameTitle, data.EntityName, pb, data.EntityName, pb, data.EntityName,
+		entityVar,
+		data.EntityName, entityVar,
+		data.ModuleNameTitle, data.ModuleNameTitle, pb, data.ModuleNameTitle, pb, data.ModuleNameTitle,
+		data.ModuleName,
+		pb, data.ModuleNameTitle,
+		entityVar, data.ModuleName,
+		data.ModuleNameTitle, data.ModuleNameTitle, data.EntityName, entityVar,
+		data.ModuleNameTitle, data.EntityName, pb, data.EntityName, pb, data.EntityName,
+		entityVar, data.EntityName,
+		data.EntityName, entityVar,
+		data.ModuleNameTitle, data.EntityName, pb, data.EntityName, pb, data.EntityName,
+		pb, data.EntityName,
+		data.EntityName, entityVar, data.EntityName, pb, data.EntityName,
+		pb, data.EntityName,
+		entityVar, entityVar, entityVar, entityVar,
+		data.EntityName)
+}
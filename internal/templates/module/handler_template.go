@@ -12,8 +12,8 @@ import (
 	"net/http"
 	"strconv"
 
-	"%s/internal/modules/%s/application/dto"
-	"%s/internal/modules/%s/application/usecases"
+	"%s/%s/application/dto"
+	"%s/%s/application/usecases"
 	"github.com/gin-gonic/gin"
 )
 
@@ -119,7 +119,7 @@ func (h *%sHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
-`, GetModulePath(), data.ModuleName, GetModulePath(), data.ModuleName,
+`, data.ModulesImportPath, data.ModuleName, data.ModulesImportPath, data.ModuleName,
 		data.ModuleNameTitle,
 		data.EntityName, data.EntityName, data.ModuleNameTitle,
 		data.EntityName, data.EntityName,
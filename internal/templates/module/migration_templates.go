@@ -0,0 +1,20 @@
+package templates
+
+import "fmt"
+
+// MigrationUpTemplate creates the table the module's repository reads and
+// writes, and its readiness check queries.
+func MigrationUpTemplate(data ModuleData) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+`, data.ModuleName)
+}
+
+func MigrationDownTemplate(data ModuleData) string {
+	return fmt.Sprintf(`DROP TABLE IF EXISTS %s;
+`, data.ModuleName)
+}
@@ -1,50 +1,260 @@
 package templates
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func ModuleTemplate(data ModuleData) string {
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`%spackage %s
 
 import (
 	"database/sql"
-
-	"github.com/gin-gonic/gin"
-	"%s/internal/modules/%s/application/usecases"
-	"%s/internal/modules/%s/infra"
+	"log/slog"
+%s%s%s%s
+	"%s/%s/application/usecases"
+	"%s/%s/infra"%s
 )
 
-type %sModule struct {
-	Handler *infra.%sHandler
+type %sModule struct {%s%s
 }
 
-func New%sModule(db *sql.DB) *%sModule {
-	repo := infra.New%sRepository(db)
+func New%sModule(db *sql.DB, logger *slog.Logger%s%s%s%s) *%sModule {
+	logger = logger.With("module", "%s")
+	repo := infra.New%sRepository(db, logger)
+%s
+	createUC := usecases.NewCreate%sUseCase(repo, logger%s)
+	getUC := usecases.NewGet%sUseCase(repo, logger)
+	listUC := usecases.NewList%sUseCase(repo, logger)
+	updateUC := usecases.NewUpdate%sUseCase(repo, logger%s)
+	deleteUC := usecases.NewDelete%sUseCase(repo, logger%s)
+%s
+	return &%sModule{%s%s
+	}
+}
+%s`, dependsHeader(data), data.ModuleName,
+		transportImports(data), moduleEventImports(data), healthImports(data), authImports(data),
+		data.ModulesImportPath, data.ModuleName,
+		data.ModulesImportPath, data.ModuleName, dependencyImports(data),
+		data.ModuleNameTitle, transportFields(data), dependencyFields(data),
+		data.ModuleNameTitle, moduleEventParams(data), healthParams(data), authParams(data), dependencyParams(data), data.ModuleNameTitle,
+		data.ModuleName,
+		data.EntityName, healthRegistration(data),
+		data.EntityName, moduleEventArgs(data), data.EntityName, data.ModuleNameTitle,
+		data.EntityName, moduleEventArgs(data), data.EntityName, moduleEventArgs(data),
+		transportConstructors(data),
+		data.ModuleNameTitle, transportAssignments(data), dependencyAssignments(data),
+		transportMethods(data))
+}
 
-	createUC := usecases.NewCreate%sUseCase(repo)
-	getUC := usecases.NewGet%sUseCase(repo)
-	listUC := usecases.NewList%sUseCase(repo)
-	updateUC := usecases.NewUpdate%sUseCase(repo)
-	deleteUC := usecases.NewDelete%sUseCase(repo)
+// The transport helpers below expose the module's use cases over each of
+// the transports given with --transport.
 
-	handler := infra.New%sHandler(createUC, getUC, listUC, updateUC, deleteUC)
+func transportImports(data ModuleData) string {
+	var b strings.Builder
+	if data.Serves("http") {
+		b.WriteString("\n\t\"github.com/gin-gonic/gin\"")
+	}
+	if data.Serves("grpc") {
+		b.WriteString("\n\t\"google.golang.org/grpc\"")
+	}
+	if data.Serves("graphql") {
+		b.WriteString("\n\t\"github.com/graphql-go/graphql\"")
+	}
+	return b.String()
+}
+
+func transportFields(data ModuleData) string {
+	var b strings.Builder
+	if data.Serves("http") {
+		fmt.Fprintf(&b, "\n\tHandler *infra.%sHandler", data.ModuleNameTitle)
+		if data.Protected {
+			b.WriteString("\n\trequireAuth gin.HandlerFunc")
+		}
+	}
+	if data.Serves("grpc") {
+		fmt.Fprintf(&b, "\n\tGRPCServer *infra.%sGRPCServer", data.ModuleNameTitle)
+	}
+	if data.Serves("graphql") {
+		fmt.Fprintf(&b, "\n\tResolver *infra.%sResolver", data.ModuleNameTitle)
+	}
+	return b.String()
+}
+
+func transportConstructors(data ModuleData) string {
+	var b strings.Builder
+	if data.Serves("http") {
+		fmt.Fprintf(&b, "\n\thandler := infra.New%sHandler(createUC, getUC, listUC, updateUC, deleteUC)\n", data.ModuleNameTitle)
+	}
+	if data.Serves("grpc") {
+		fmt.Fprintf(&b, "\n\tgrpcServer := infra.New%sGRPCServer(createUC, getUC, listUC, updateUC, deleteUC)\n", data.ModuleNameTitle)
+	}
+	if data.Serves("graphql") {
+		fmt.Fprintf(&b, "\n\tresolver := infra.New%sResolver(createUC, getUC, listUC, updateUC, deleteUC)\n", data.ModuleNameTitle)
+	}
+	return b.String()
+}
 
-	return &%sModule{
-		Handler: handler,
+func transportAssignments(data ModuleData) string {
+	var b strings.Builder
+	if data.Serves("http") {
+		b.WriteString("\n\t\tHandler: handler,")
+		if data.Protected {
+			b.WriteString("\n\t\trequireAuth: middleware.RequireAuth(verifier),")
+		}
+	}
+	if data.Serves("grpc") {
+		b.WriteString("\n\t\tGRPCServer: grpcServer,")
+	}
+	if data.Serves("graphql") {
+		b.WriteString("\n\t\tResolver: resolver,")
 	}
+	return b.String()
 }
 
+func transportMethods(data ModuleData) string {
+	var b strings.Builder
+	if data.Serves("http") {
+		fmt.Fprintf(&b, `
 func (m *%sModule) RegisterRoutes(r *gin.RouterGroup) {
-	infra.RegisterRoutes(r, m.Handler)
-}
-`, data.ModuleName,
-		GetModulePath(), data.ModuleName,
-		GetModulePath(), data.ModuleName,
-		data.ModuleNameTitle, data.ModuleNameTitle,
-		data.ModuleNameTitle, data.ModuleNameTitle,
-		data.EntityName,
-		data.EntityName, data.EntityName, data.ModuleNameTitle,
-		data.EntityName, data.EntityName,
-		data.ModuleNameTitle,
-		data.ModuleNameTitle,
-		data.ModuleNameTitle)
+	infra.RegisterRoutes(r, m.Handler%s)
+}
+`, data.ModuleNameTitle, moduleRouteArgs(data))
+	}
+	if data.Serves("grpc") {
+		fmt.Fprintf(&b, `
+func (m *%sModule) RegisterGRPC(s *grpc.Server) {
+	infra.RegisterGRPCService(s, m.GRPCServer)
+}
+`, data.ModuleNameTitle)
+	}
+	if data.Serves("graphql") {
+		fmt.Fprintf(&b, `
+func (m *%sModule) RegisterGraphQL(queries, mutations graphql.Fields) {
+	infra.RegisterGraphQL(queries, mutations, m.Resolver)
+}
+`, data.ModuleNameTitle)
+	}
+	return b.String()
+}
+
+// The module event helpers pass the container's event bus and transactor on
+// to the use cases that publish events.
+
+func moduleEventImports(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return fmt.Sprintf("\n\t\"%s/internal/domain/events\"", data.ModulePath)
+}
+
+func moduleEventParams(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return ", bus events.Publisher, transactor events.Transactor"
+}
+
+func moduleEventArgs(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return ", bus, transactor"
+}
+
+// The health helpers register a readiness check of the module's table with
+// the container's health checks.
+
+func healthImports(data ModuleData) string {
+	if !data.HealthChecks {
+		return ""
+	}
+	return fmt.Sprintf("\n\t\"%s/internal/domain/checks\"", data.ModulePath)
+}
+
+func healthParams(data ModuleData) string {
+	if !data.HealthChecks {
+		return ""
+	}
+	return ", healthChecks *checks.Registry"
+}
+
+func healthRegistration(data ModuleData) string {
+	if !data.HealthChecks {
+		return ""
+	}
+	return fmt.Sprintf("\thealthChecks.Register(checks.Func(%q, repo.Ping))\n", data.ModuleName)
+}
+
+// The auth helpers give a --protected module the token verifier its routes
+// are checked with.
+
+func authImports(data ModuleData) string {
+	if !data.Protected {
+		return ""
+	}
+	return fmt.Sprintf("\n\t\"%s/internal/domain/security\"\n\t\"%s/internal/infrastructure/http/middleware\"", data.ModulePath, data.ModulePath)
+}
+
+func moduleRouteArgs(data ModuleData) string {
+	if !data.Protected {
+		return ""
+	}
+	return ", m.requireAuth"
+}
+
+func authParams(data ModuleData) string {
+	if !data.Protected {
+		return ""
+	}
+	return ", verifier security.TokenVerifier"
+}
+
+// dependsHeader records the module's --depends flag so that
+// `gozilla sync container` can rebuild the wiring in dependency order.
+func dependsHeader(data ModuleData) string {
+	if len(data.Dependencies) == 0 {
+		return ""
+	}
+	return "//gozilla:depends " + strings.Join(data.Dependencies, ",") + "\n\n"
+}
+
+// The helpers below wire the modules listed in --depends into the module:
+// each one is imported, received by New<X>Module and kept on the struct.
+
+func dependencyImports(data ModuleData) string {
+	var b strings.Builder
+	for _, dep := range data.Dependencies {
+		fmt.Fprintf(&b, "\n\t\"%s/%s\"", data.ModulesImportPath, dep)
+	}
+	return b.String()
+}
+
+func dependencyFields(data ModuleData) string {
+	if len(data.Dependencies) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, dep := range data.Dependencies {
+		fmt.Fprintf(&b, "\n\t%sModule *%s.%sModule", strings.Title(dep), dep, strings.Title(dep))
+	}
+	return b.String()
+}
+
+func dependencyParams(data ModuleData) string {
+	var b strings.Builder
+	for _, dep := range data.Dependencies {
+		fmt.Fprintf(&b, ", %sModule *%s.%sModule", dep, dep, strings.Title(dep))
+	}
+	return b.String()
+}
+
+func dependencyAssignments(data ModuleData) string {
+	var b strings.Builder
+	for _, dep := range data.Dependencies {
+		fmt.Fprintf(&b, "\n\t\t%sModule: %sModule,", strings.Title(dep), dep)
+	}
+	return b.String()
 }
@@ -0,0 +1,69 @@
+package templates
+
+import "fmt"
+
+func ProtoTemplate(data ModuleData) string {
+	return fmt.Sprintf(`syntax = "proto3";
+
+package %s.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "%s/%s/infra/proto;%spb";
+
+service %sService {
+  rpc Create%s(Create%sRequest) returns (%s);
+  rpc Get%s(Get%sRequest) returns (%s);
+  rpc List%s(List%sRequest) returns (List%sResponse);
+  rpc Update%s(Update%sRequest) returns (%s);
+  rpc Delete%s(Delete%sRequest) returns (Delete%sResponse);
+}
+
+message %s {
+  int64 id = 1;
+  string name = 2;
+  google.protobuf.Timestamp created_at = 3;
+  google.protobuf.Timestamp updated_at = 4;
+}
+
+message Create%sRequest {
+  string name = 1;
+}
+
+message Get%sRequest {
+  int64 id = 1;
+}
+
+message List%sRequest {}
+
+message List%sResponse {
+  repeated %s %s = 1;
+}
+
+message Update%sRequest {
+  int64 id = 1;
+  string name = 2;
+}
+
+message Delete%sRequest {
+  int64 id = 1;
+}
+
+message Delete%sResponse {}
+`, data.ModuleName,
+		data.ModulesImportPath, data.ModuleName, data.ModuleName,
+		data.ModuleNameTitle,
+		data.EntityName, data.EntityName, data.EntityName,
+		data.EntityName, data.EntityName, data.EntityName,
+		data.ModuleNameTitle, data.ModuleNameTitle, data.ModuleNameTitle,
+		data.EntityName, data.EntityName, data.EntityName,
+		data.EntityName, data.EntityName, data.EntityName,
+		data.EntityName,
+		data.EntityName,
+		data.EntityName,
+		data.ModuleNameTitle,
+		data.ModuleNameTitle, data.EntityName, data.ModuleName,
+		data.EntityName,
+		data.EntityName,
+		data.EntityName)
+}
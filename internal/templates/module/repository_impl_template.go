@@ -7,52 +7,64 @@ import (
 
 func RepositoryImplTemplate(data ModuleData) string {
 	entityVar := strings.ToLower(data.EntityName[:1])
+	executor := "r.db"
+	if data.Events {
+		// Join the use case's transaction, if any, so that changes and the
+		// events they raise are committed together.
+		executor = "database.Conn(ctx, r.db)"
+	}
 	return fmt.Sprintf(`package infra
 
 import (
 	"context"
 	"database/sql"
+	"log/slog"
 
-	"%s/internal/modules/%s/domain"
+	"%s/%s/domain"%s
 )
 
 type %sRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
 }
 
-func New%sRepository(db *sql.DB) *%sRepository {
+func New%sRepository(db *sql.DB, logger *slog.Logger) *%sRepository {
 	return &%sRepository{
-		db: db,
+		db:     db,
+		logger: logger,
 	}
 }
 
 func (r *%sRepository) Create(ctx context.Context, %s *domain.%s) error {
-	query := `+"`"+`
+%s	query := `+"`"+`
 		INSERT INTO %s (name, created_at, updated_at)
 		VALUES ($1, $2, $3)
 		RETURNING id
 	`+"`"+`
 
-	err := r.db.QueryRowContext(
+	err := %s.QueryRowContext(
 		ctx,
 		query,
 		%s.Name,
 		%s.CreatedAt,
 		%s.UpdatedAt,
 	).Scan(&%s.ID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "insert failed", "error", err)%s
+	}
 
 	return err
 }
 
 func (r *%sRepository) GetByID(ctx context.Context, id int64) (*domain.%s, error) {
-	query := `+"`"+`
+%s	query := `+"`"+`
 		SELECT id, name, created_at, updated_at
 		FROM %s
 		WHERE id = $1
 	`+"`"+`
 
 	%s := &domain.%s{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := %s.QueryRowContext(ctx, query, id).Scan(
 		&%s.ID,
 		&%s.Name,
 		&%s.CreatedAt,
@@ -64,6 +76,7 @@ func (r *%sRepository) GetByID(ctx context.Context, id int64) (*domain.%s, error
 	}
 
 	if err != nil {
+		r.logger.ErrorContext(ctx, "select failed", "id", id, "error", err)%s
 		return nil, err
 	}
 
@@ -71,14 +84,15 @@ func (r *%sRepository) GetByID(ctx context.Context, id int64) (*domain.%s, error
 }
 
 func (r *%sRepository) List(ctx context.Context) ([]*domain.%s, error) {
-	query := `+"`"+`
+%s	query := `+"`"+`
 		SELECT id, name, created_at, updated_at
 		FROM %s
 		ORDER BY created_at DESC
 	`+"`"+`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := %s.QueryContext(ctx, query)
 	if err != nil {
+		r.logger.ErrorContext(ctx, "list failed", "error", err)%s
 		return nil, err
 	}
 	defer rows.Close()
@@ -101,50 +115,79 @@ func (r *%sRepository) List(ctx context.Context) ([]*domain.%s, error) {
 }
 
 func (r *%sRepository) Update(ctx context.Context, %s *domain.%s) error {
-	query := `+"`"+`
+%s	query := `+"`"+`
 		UPDATE %s
 		SET name = $1, updated_at = $2
 		WHERE id = $3
 	`+"`"+`
 
-	_, err := r.db.ExecContext(
+	_, err := %s.ExecContext(
 		ctx,
 		query,
 		%s.Name,
 		%s.UpdatedAt,
 		%s.ID,
 	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "update failed", "id", %s.ID, "error", err)%s
+	}
 
 	return err
 }
 
 func (r *%sRepository) Delete(ctx context.Context, id int64) error {
-	query := `+"`DELETE FROM %s WHERE id = $1`"+`
-	_, err := r.db.ExecContext(ctx, query, id)
+%s	query := `+"`DELETE FROM %s WHERE id = $1`"+`
+	_, err := %s.ExecContext(ctx, query, id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "delete failed", "id", id, "error", err)%s
+	}
 	return err
 }
-`, GetModulePath(), data.ModuleName,
+%s`, data.ModulesImportPath, data.ModuleName, repositoryImports(data),
 		data.EntityName,
 		data.EntityName, data.EntityName,
 		data.EntityName,
 		data.EntityName, entityVar, data.EntityName,
-		data.ModuleName,
-		entityVar, entityVar, entityVar, entityVar,
+		querySpan(data, "Create", "INSERT"), data.ModuleName, executor,
+		entityVar, entityVar, entityVar, entityVar, spanError(data),
 		data.EntityName, data.EntityName,
-		data.ModuleName,
-		entityVar, data.EntityName,
+		querySpan(data, "GetByID", "SELECT"), data.ModuleName,
+		entityVar, data.EntityName, executor,
 		entityVar, entityVar, entityVar, entityVar,
 		data.EntityName,
+		spanError(data),
 		entityVar,
 		data.EntityName, data.EntityName,
-		data.ModuleName,
+		querySpan(data, "List", "SELECT"), data.ModuleName, executor, spanError(data),
 		data.ModuleName, data.EntityName,
 		entityVar, data.EntityName,
 		entityVar, entityVar, entityVar, entityVar,
 		data.ModuleName, data.ModuleName, entityVar,
 		data.ModuleName,
 		data.EntityName, entityVar, data.EntityName,
-		data.ModuleName,
-		entityVar, entityVar, entityVar,
-		data.EntityName, data.ModuleName)
+		querySpan(data, "Update", "UPDATE"), data.ModuleName, executor,
+		entityVar, entityVar, entityVar, entityVar, spanError(data),
+		data.EntityName, querySpan(data, "Delete", "DELETE"), data.ModuleName, executor, spanError(data),
+		repositoryPing(data))
+}
+
+func repositoryImports(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return fmt.Sprintf("\n\t\"%s/internal/infrastructure/database\"", data.ModulePath)
+}
+
+// repositoryPing lets the module's readiness check see whether its table can
+// be queried, which fails while the database is down or not migrated.
+func repositoryPing(data ModuleData) string {
+	if !data.HealthChecks {
+		return ""
+	}
+	return fmt.Sprintf(`
+func (r *%sRepository) Ping(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `+"`SELECT 1 FROM %s LIMIT 1`"+`)
+	return err
+}
+`, data.EntityName, data.ModuleName)
 }
@@ -5,20 +5,67 @@ import "fmt"
 func RoutesTemplate(data ModuleData) string {
 	return fmt.Sprintf(`package infra
 
-import "github.com/gin-gonic/gin"
+%s
 
-func RegisterRoutes(r *gin.RouterGroup, handler *%sHandler) {
-	%s := r.Group("/%s")
+func RegisterRoutes(r *gin.RouterGroup, handler *%sHandler%s) {%s
+	%s := r.Group("/%s"%s)
 	{
-		%s.POST("", handler.Create)
-		%s.GET("", handler.List)
-		%s.GET("/:id", handler.Get)
-		%s.PUT("/:id", handler.Update)
-		%s.DELETE("/:id", handler.Delete)
+		%s.POST("", %shandler.Create)
+		%s.GET("", %shandler.List)
+		%s.GET("/:id", %shandler.Get)
+		%s.PUT("/:id", %shandler.Update)
+		%s.DELETE("/:id", %shandler.Delete)
 	}
 }
-`, data.ModuleNameTitle,
-		data.ModuleName, data.ModuleName,
-		data.ModuleName, data.ModuleName, data.ModuleName,
-		data.ModuleName, data.ModuleName)
+`, routeImports(data), data.ModuleNameTitle, routeParams(data), routeComment(data),
+		data.ModuleName, data.ModuleName, routeMiddleware(data),
+		data.ModuleName, routeAuthz(data, "create"),
+		data.ModuleName, routeAuthz(data, "list"),
+		data.ModuleName, routeAuthz(data, "get"),
+		data.ModuleName, routeAuthz(data, "update"),
+		data.ModuleName, routeAuthz(data, "delete"))
+}
+
+// The route helpers below put every route of a --protected module behind
+// the auth middleware the module hands to RegisterRoutes.
+
+func routeImports(data ModuleData) string {
+	if data.Authz == nil {
+		return `import "github.com/gin-gonic/gin"`
+	}
+	return fmt.Sprintf(`import (
+	"%s/internal/infrastructure/http/middleware"
+	"github.com/gin-gonic/gin"
+)`, data.ModulePath)
+}
+
+func routeParams(data ModuleData) string {
+	if !data.Protected {
+		return ""
+	}
+	return ", requireAuth gin.HandlerFunc"
+}
+
+func routeComment(data ModuleData) string {
+	if !data.Protected {
+		return ""
+	}
+	return "\n\t// Every route of the group requires an access token"
+}
+
+func routeMiddleware(data ModuleData) string {
+	if !data.Protected {
+		return ""
+	}
+	return ", requireAuth"
+}
+
+// routeAuthz checks the permission of the action on top of the token, for
+// modules generated with --authz. The roles granted each permission live
+// in security.Permissions.
+func routeAuthz(data ModuleData, action string) string {
+	if data.Authz == nil {
+		return ""
+	}
+	return fmt.Sprintf("middleware.Authorize(%q), ", data.Permission(action))
 }
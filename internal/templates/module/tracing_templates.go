@@ -0,0 +1,81 @@
+package templates
+
+import "fmt"
+
+// UseCaseTracerTemplate declares the tracer of the module's use cases.
+func UseCaseTracerTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package usecases
+
+import "go.opentelemetry.io/otel"
+
+var tracer = otel.Tracer("%s/%s/application/usecases")
+`, data.ModulesImportPath, data.ModuleName)
+}
+
+// RepositoryTracingTemplate declares the tracer of the module's repository
+// and the helpers its queries use.
+func RepositoryTracingTemplate(data ModuleData) string {
+	return fmt.Sprintf(`package infra
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("%s/%s/infra")
+
+// startQuerySpan starts the span of a query on the %s table.
+func startQuerySpan(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.collection.name", "%s"),
+			attribute.String("db.operation.name", operation),
+		),
+	)
+}
+
+// recordError marks span as failed with err.
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+`, data.ModulesImportPath, data.ModuleName, data.ModuleName, data.ModuleName)
+}
+
+// The tracing helpers below add spans to the use cases and repository of
+// modules generated in projects with telemetry.
+
+// useCaseSpan starts the span of a use case, named like "users.CreateUser".
+func useCaseSpan(data ModuleData, name string) string {
+	if !data.Tracing {
+		return ""
+	}
+	return fmt.Sprintf(`	ctx, span := tracer.Start(ctx, "%s.%s")
+	defer span.End()
+
+`, data.ModuleName, name)
+}
+
+// querySpan starts the span of a repository method running a SQL operation.
+func querySpan(data ModuleData, method, operation string) string {
+	if !data.Tracing {
+		return ""
+	}
+	return fmt.Sprintf(`	ctx, span := startQuerySpan(ctx, "%sRepository.%s", "%s")
+	defer span.End()
+
+`, data.EntityName, method, operation)
+}
+
+func spanError(data ModuleData) string {
+	if !data.Tracing {
+		return ""
+	}
+	return "\n\t\trecordError(span, err)"
+}
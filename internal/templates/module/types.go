@@ -1,32 +1,58 @@
 package templates
 
-import (
-	"os"
-	"strings"
-)
+import "github.com/pierslabs/gozilla-cli/internal/project"
 
 type ModuleData struct {
-	ModuleName       string
-	ModuleNameTitle  string
-	ModuleNameUpper  string
-	EntityName       string
-	EntityNamePlural string
-	Dependencies     []string
+	// ModulePath is the go.mod module path of the project, which the
+	// imports of the generated code start with.
+	ModulePath string
+	// ModulesImportPath is the import path of the directory the modules
+	// live in, e.g. example.com/shop/internal/modules.
+	ModulesImportPath string
+	ModuleName        string
+	ModuleNameTitle   string
+	ModuleNameUpper   string
+	EntityName        string
+	EntityNamePlural  string
+	Dependencies      []string
+	Transports        []string
+	// Events is set in projects with the event bus and outbox; the module
+	// then publishes <Entity>Created/Updated/Deleted from its use cases.
+	Events bool
+	// Protected puts every HTTP route of the module behind the auth
+	// middleware of `gozilla add auth`.
+	Protected bool
+	// Authz maps actions (create, get, list, update, delete) to the roles
+	// granted them; each route then checks its "<module>:<action>"
+	// permission. Only set on protected modules.
+	Authz map[string][]string
+	// Tracing is set in projects with telemetry; the use cases and the
+	// repository then run in OpenTelemetry spans.
+	Tracing bool
+	// HealthChecks is set in projects with the health check registry; the
+	// module then registers a readiness check of its table.
+	HealthChecks bool
 }
 
-// GetModulePath returns the module path from go.mod
-func GetModulePath() string {
-	data, err := os.ReadFile("go.mod")
-	if err != nil {
-		return "app" // fallback
-	}
+// Permission returns the name of the permission guarding an action.
+func (d ModuleData) Permission(action string) string {
+	return d.ModuleName + ":" + action
+}
 
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+// Serves reports whether the module is exposed over the given transport
+// ("http", "grpc" or "graphql").
+func (d ModuleData) Serves(transport string) bool {
+	for _, t := range d.Transports {
+		if t == transport {
+			return true
 		}
 	}
+	return false
+}
 
-	return "app" // fallback
+// GetModulePath returns the module path from the go.mod of the project in
+// the working directory. Generated imports start with it, so a go.mod
+// without one is an error rather than a guess.
+func GetModulePath() (string, error) {
+	return project.ModulePath("go.mod")
 }
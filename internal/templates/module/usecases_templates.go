@@ -11,42 +11,44 @@ func CreateUseCaseTemplate(data ModuleData) string {
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
-	"%s/internal/modules/%s/application/dto"
-	"%s/internal/modules/%s/domain"
+	"%s/%s/application/dto"
+	"%s/%s/domain"%s
 )
 
 type Create%sUseCase struct {
-	repo domain.%sRepository
+	repo   domain.%sRepository
+	logger *slog.Logger%s
 }
 
-func NewCreate%sUseCase(repo domain.%sRepository) *Create%sUseCase {
+func NewCreate%sUseCase(repo domain.%sRepository, logger *slog.Logger%s) *Create%sUseCase {
 	return &Create%sUseCase{
-		repo: repo,
+		repo:   repo,
+		logger: logger,%s
 	}
 }
 
 func (uc *Create%sUseCase) Execute(ctx context.Context, input dto.Create%sDTO) (*domain.%s, error) {
-	%s := &domain.%s{
+%s	%s := &domain.%s{
 		Name:      input.Name,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := uc.repo.Create(ctx, %s); err != nil {
-		return nil, err
-	}
-
+%s
+	uc.logger.InfoContext(ctx, "%s %s", "id", %s.ID)
 	return %s, nil
 }
-`, GetModulePath(), data.ModuleName, GetModulePath(), data.ModuleName,
-		data.EntityName, data.EntityName,
-		data.EntityName, data.EntityName, data.EntityName,
-		data.EntityName,
+`, data.ModulesImportPath, data.ModuleName, data.ModulesImportPath, data.ModuleName, eventImports(data),
+		data.EntityName, data.EntityName, eventFields(data),
+		data.EntityName, data.EntityName, eventParams(data), data.EntityName,
+		data.EntityName, eventAssignments(data),
 		data.EntityName, data.EntityName, data.EntityName,
-		entityVar, data.EntityName,
-		entityVar,
+		useCaseSpan(data, "Create"+data.EntityName), entityVar, data.EntityName,
+		persist(data, "Create", entityVar, fmt.Sprintf("domain.%sCreated{ID: %s.ID, Name: %s.Name}", data.EntityName, entityVar, entityVar)),
+		strings.ToLower(data.EntityName), "created", entityVar,
 		entityVar)
 }
 
@@ -55,28 +57,32 @@ func GetUseCaseTemplate(data ModuleData) string {
 
 import (
 	"context"
+	"log/slog"
 
-	"%s/internal/modules/%s/domain"
+	"%s/%s/domain"
 )
 
 type Get%sUseCase struct {
-	repo domain.%sRepository
+	repo   domain.%sRepository
+	logger *slog.Logger
 }
 
-func NewGet%sUseCase(repo domain.%sRepository) *Get%sUseCase {
+func NewGet%sUseCase(repo domain.%sRepository, logger *slog.Logger) *Get%sUseCase {
 	return &Get%sUseCase{
-		repo: repo,
+		repo:   repo,
+		logger: logger,
 	}
 }
 
 func (uc *Get%sUseCase) Execute(ctx context.Context, id int64) (*domain.%s, error) {
-	return uc.repo.GetByID(ctx, id)
+%s	return uc.repo.GetByID(ctx, id)
 }
-`, GetModulePath(), data.ModuleName,
+`, data.ModulesImportPath, data.ModuleName,
 		data.EntityName, data.EntityName,
 		data.EntityName, data.EntityName, data.EntityName,
 		data.EntityName,
-		data.EntityName, data.EntityName)
+		data.EntityName, data.EntityName,
+		useCaseSpan(data, "Get"+data.EntityName))
 }
 
 func ListUseCaseTemplate(data ModuleData) string {
@@ -84,53 +90,61 @@ func ListUseCaseTemplate(data ModuleData) string {
 
 import (
 	"context"
+	"log/slog"
 
-	"%s/internal/modules/%s/domain"
+	"%s/%s/domain"
 )
 
 type List%sUseCase struct {
-	repo domain.%sRepository
+	repo   domain.%sRepository
+	logger *slog.Logger
 }
 
-func NewList%sUseCase(repo domain.%sRepository) *List%sUseCase {
+func NewList%sUseCase(repo domain.%sRepository, logger *slog.Logger) *List%sUseCase {
 	return &List%sUseCase{
-		repo: repo,
+		repo:   repo,
+		logger: logger,
 	}
 }
 
 func (uc *List%sUseCase) Execute(ctx context.Context) ([]*domain.%s, error) {
-	return uc.repo.List(ctx)
+%s	return uc.repo.List(ctx)
 }
-`, GetModulePath(), data.ModuleName,
+`, data.ModulesImportPath, data.ModuleName,
 		data.ModuleNameTitle, data.EntityName,
 		data.ModuleNameTitle, data.EntityName, data.ModuleNameTitle,
 		data.ModuleNameTitle,
-		data.ModuleNameTitle, data.EntityName)
+		data.ModuleNameTitle, data.EntityName,
+		useCaseSpan(data, "List"+data.ModuleNameTitle))
 }
 
 func UpdateUseCaseTemplate(data ModuleData) string {
+	entityVar := strings.ToLower(data.EntityName[:1])
 	return fmt.Sprintf(`package usecases
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
-	"%s/internal/modules/%s/application/dto"
-	"%s/internal/modules/%s/domain"
+	"%s/%s/application/dto"
+	"%s/%s/domain"%s
 )
 
 type Update%sUseCase struct {
-	repo domain.%sRepository
+	repo   domain.%sRepository
+	logger *slog.Logger%s
 }
 
-func NewUpdate%sUseCase(repo domain.%sRepository) *Update%sUseCase {
+func NewUpdate%sUseCase(repo domain.%sRepository, logger *slog.Logger%s) *Update%sUseCase {
 	return &Update%sUseCase{
-		repo: repo,
+		repo:   repo,
+		logger: logger,%s
 	}
 }
 
 func (uc *Update%sUseCase) Execute(ctx context.Context, id int64, input dto.Update%sDTO) (*domain.%s, error) {
-	%s, err := uc.repo.GetByID(ctx, id)
+%s	%s, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -140,22 +154,21 @@ func (uc *Update%sUseCase) Execute(ctx context.Context, id int64, input dto.Upda
 	}
 	%s.UpdatedAt = time.Now()
 
-	if err := uc.repo.Update(ctx, %s); err != nil {
-		return nil, err
-	}
-
+%s
+	uc.logger.InfoContext(ctx, "%s %s", "id", %s.ID)
 	return %s, nil
 }
-`, GetModulePath(), data.ModuleName, GetModulePath(), data.ModuleName,
-		data.EntityName, data.EntityName,
-		data.EntityName, data.EntityName, data.EntityName,
-		data.EntityName,
+`, data.ModulesImportPath, data.ModuleName, data.ModulesImportPath, data.ModuleName, eventImports(data),
+		data.EntityName, data.EntityName, eventFields(data),
+		data.EntityName, data.EntityName, eventParams(data), data.EntityName,
+		data.EntityName, eventAssignments(data),
 		data.EntityName, data.EntityName, data.EntityName,
-		strings.ToLower(data.EntityName[:1]),
-		strings.ToLower(data.EntityName[:1]),
-		strings.ToLower(data.EntityName[:1]),
-		strings.ToLower(data.EntityName[:1]),
-		strings.ToLower(data.EntityName[:1]))
+		useCaseSpan(data, "Update"+data.EntityName), entityVar,
+		entityVar,
+		entityVar,
+		persist(data, "Update", entityVar, fmt.Sprintf("domain.%sUpdated{ID: %s.ID, Name: %s.Name}", data.EntityName, entityVar, entityVar)),
+		strings.ToLower(data.EntityName), "updated", entityVar,
+		entityVar)
 }
 
 func DeleteUseCaseTemplate(data ModuleData) string {
@@ -163,26 +176,102 @@ func DeleteUseCaseTemplate(data ModuleData) string {
 
 import (
 	"context"
+	"log/slog"
 
-	"%s/internal/modules/%s/domain"
+	"%s/%s/domain"%s
 )
 
 type Delete%sUseCase struct {
-	repo domain.%sRepository
+	repo   domain.%sRepository
+	logger *slog.Logger%s
 }
 
-func NewDelete%sUseCase(repo domain.%sRepository) *Delete%sUseCase {
+func NewDelete%sUseCase(repo domain.%sRepository, logger *slog.Logger%s) *Delete%sUseCase {
 	return &Delete%sUseCase{
-		repo: repo,
+		repo:   repo,
+		logger: logger,%s
 	}
 }
 
 func (uc *Delete%sUseCase) Execute(ctx context.Context, id int64) error {
-	return uc.repo.Delete(ctx, id)
+%s%s
+	uc.logger.InfoContext(ctx, "%s deleted", "id", id)
+	return nil
 }
-`, GetModulePath(), data.ModuleName,
-		data.EntityName, data.EntityName,
-		data.EntityName, data.EntityName, data.EntityName,
+`, data.ModulesImportPath, data.ModuleName, eventImports(data),
+		data.EntityName, data.EntityName, eventFields(data),
+		data.EntityName, data.EntityName, eventParams(data), data.EntityName,
+		data.EntityName, eventAssignments(data),
 		data.EntityName,
-		data.EntityName)
+		useCaseSpan(data, "Delete"+data.EntityName), persistDelete(data),
+		strings.ToLower(data.EntityName))
+}
+
+// The event helpers below make the write use cases publish their domain
+// event in the same transaction as the change, when the project has events.
+
+func eventImports(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return fmt.Sprintf("\n\t\"%s/internal/domain/events\"", data.ModulePath)
+}
+
+func eventFields(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return "\n\tpublisher  events.Publisher\n\ttransactor events.Transactor"
+}
+
+func eventParams(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return ", publisher events.Publisher, transactor events.Transactor"
+}
+
+func eventAssignments(data ModuleData) string {
+	if !data.Events {
+		return ""
+	}
+	return "\n\t\tpublisher:  publisher,\n\t\ttransactor: transactor,"
+}
+
+// persist saves entityVar with the repository method op and, with events,
+// publishes event in the same transaction.
+func persist(data ModuleData, op, entityVar, event string) string {
+	if !data.Events {
+		return fmt.Sprintf(`	if err := uc.repo.%s(ctx, %s); err != nil {
+		return nil, err
+	}
+`, op, entityVar)
+	}
+	return fmt.Sprintf(`	if err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.%s(ctx, %s); err != nil {
+			return err
+		}
+		return uc.publisher.Publish(ctx, %s)
+	}); err != nil {
+		return nil, err
+	}
+`, op, entityVar, event)
+}
+
+func persistDelete(data ModuleData) string {
+	if !data.Events {
+		return `	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+`
+	}
+	return fmt.Sprintf(`	if err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return uc.publisher.Publish(ctx, domain.%sDeleted{ID: id})
+	}); err != nil {
+		return err
+	}
+`, data.EntityName)
 }
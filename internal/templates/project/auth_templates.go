@@ -0,0 +1,245 @@
+package templates
+
+import "fmt"
+
+func SecurityTemplate(data ProjectData) string {
+	return `package security
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Principal is the authenticated user a request acts for.
+type Principal struct {
+	UserID int64    ` + "`json:\"user_id\"`" + `
+	Email  string   ` + "`json:\"email\"`" + `
+	Roles  []string ` + "`json:\"roles\"`" + `
+}
+
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type TokenPair struct {
+	AccessToken  string ` + "`json:\"access_token\"`" + `
+	RefreshToken string ` + "`json:\"refresh_token\"`" + `
+	ExpiresIn    int64  ` + "`json:\"expires_in\"`" + ` // seconds until the access token expires
+}
+
+// TokenIssuer issues the tokens a principal authenticates with, and
+// checks refresh tokens.
+type TokenIssuer interface {
+	Issue(p Principal) (TokenPair, error)
+	VerifyRefresh(refreshToken string) (Principal, error)
+}
+
+// TokenVerifier checks access tokens.
+type TokenVerifier interface {
+	Verify(accessToken string) (Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal the auth middleware put in ctx.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+`
+}
+
+func JWTTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package token
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"%s/internal/domain/security"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	accessToken  = "access"
+	refreshToken = "refresh"
+)
+
+type claims struct {
+	Email string   `+"`json:\"email\"`"+`
+	Roles []string `+"`json:\"roles\"`"+`
+	Type  string   `+"`json:\"typ\"`"+`
+	jwt.RegisteredClaims
+}
+
+// JWT issues and verifies HS256-signed access and refresh tokens. Without
+// a secret it issues nothing and rejects every token.
+type JWT struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewJWT(secret string, accessTTL, refreshTTL time.Duration) *JWT {
+	return &JWT{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (j *JWT) Issue(p security.Principal) (security.TokenPair, error) {
+	if len(j.secret) == 0 {
+		return security.TokenPair{}, errors.New("JWT_SECRET is not set")
+	}
+
+	access, err := j.sign(p, accessToken, j.accessTTL)
+	if err != nil {
+		return security.TokenPair{}, err
+	}
+
+	refresh, err := j.sign(p, refreshToken, j.refreshTTL)
+	if err != nil {
+		return security.TokenPair{}, err
+	}
+
+	return security.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(j.accessTTL.Seconds()),
+	}, nil
+}
+
+func (j *JWT) Verify(token string) (security.Principal, error) {
+	return j.parse(token, accessToken)
+}
+
+func (j *JWT) VerifyRefresh(token string) (security.Principal, error) {
+	return j.parse(token, refreshToken)
+}
+
+func (j *JWT) sign(p security.Principal, typ string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	c := claims{
+		Email: p.Email,
+		Roles: p.Roles,
+		Type:  typ,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(p.UserID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(j.secret)
+}
+
+func (j *JWT) parse(token, typ string) (security.Principal, error) {
+	if len(j.secret) == 0 {
+		return security.Principal{}, security.ErrInvalidToken
+	}
+
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return j.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || c.Type != typ {
+		return security.Principal{}, security.ErrInvalidToken
+	}
+
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil {
+		return security.Principal{}, security.ErrInvalidToken
+	}
+
+	return security.Principal{UserID: id, Email: c.Email, Roles: c.Roles}, nil
+}
+`, data.ModulePath)
+}
+
+func AuthMiddlewareTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"%s/internal/domain/security"
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalKey is the gin context key holding the security.Principal.
+const PrincipalKey = "principal"
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer"
+// access token. The principal it was issued to is put in the gin context
+// and in the request context, for security.PrincipalFrom.
+func RequireAuth(verifier security.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		principal, err := verifier.Verify(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Request = c.Request.WithContext(security.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+`, data.ModulePath)
+}
+
+// AuthEnvExample holds the settings `gozilla add auth --jwt` adds to
+// .env.example.
+func AuthEnvExample() string {
+	return `JWT_SECRET=change-me
+JWT_ACCESS_TTL=15m
+JWT_REFRESH_TTL=720h
+`
+}
+
+func AuthMigrationUpTemplate(data ProjectData) string {
+	return `CREATE TABLE IF NOT EXISTS auth_users (
+    id            BIGSERIAL PRIMARY KEY,
+    email         TEXT        NOT NULL UNIQUE,
+    password_hash TEXT        NOT NULL,
+    roles         TEXT[]      NOT NULL DEFAULT '{user}',
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+`
+}
+
+func AuthMigrationDownTemplate(data ProjectData) string {
+	return `DROP TABLE IF EXISTS auth_users;
+`
+}
+
+// DurationEnvHelper reads settings like JWT_ACCESS_TTL=15m in config.go.
+func DurationEnvHelper() string {
+	return `
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+`
+}
@@ -0,0 +1,59 @@
+package templates
+
+import "fmt"
+
+func PermissionsTemplate(data ProjectData) string {
+	return `package security
+
+import "slices"
+
+// AnyRole grants a permission to every authenticated principal.
+const AnyRole = "any"
+
+// Permissions maps each permission, "<module>:<action>", to the roles
+// granted it. gozilla adds the permissions of modules generated with
+// --authz; edit the roles here to change who may do what.
+var Permissions = map[string][]string{}
+
+// Allowed reports whether p holds a role granted the permission.
+// Permissions missing from the registry are denied to everyone.
+func Allowed(p Principal, permission string) bool {
+	roles := Permissions[permission]
+	if slices.Contains(roles, AnyRole) {
+		return true
+	}
+	return slices.ContainsFunc(roles, p.HasRole)
+}
+`
+}
+
+func AuthorizeMiddlewareTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package middleware
+
+import (
+	"net/http"
+
+	"%s/internal/domain/security"
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize lets a request through only if the principal put in the
+// context by RequireAuth is allowed the permission by security.Permissions.
+func Authorize(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := security.PrincipalFrom(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+			return
+		}
+
+		if !security.Allowed(principal, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing permission " + permission})
+			return
+		}
+
+		c.Next()
+	}
+}
+`, data.ModulePath)
+}
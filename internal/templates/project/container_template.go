@@ -6,21 +6,51 @@ func ContainerTemplate(data ProjectData) string {
 	return fmt.Sprintf(`package container
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
 
+	"%s/internal/domain/checks"
+	"%s/internal/domain/events"
+	"%s/internal/infrastructure/config"
+	"%s/internal/infrastructure/database"
+	"%s/internal/infrastructure/outbox"
 	"%s/internal/modules/health"
 	"github.com/gin-gonic/gin"
 )
 
 type Container struct {
 	DB           *sql.DB
+	Logger       *slog.Logger
+	Events       *outbox.Bus
+	Transactor   *database.Transactor
+	HealthChecks *checks.Registry
 	HealthModule *health.HealthModule
 }
 
-func NewContainer(db *sql.DB) *Container {
+func NewContainer(cfg *config.Config, db *sql.DB, logger *slog.Logger) *Container {
+	// Events are written to the outbox in the transaction of the change
+	// that raised them, and relayed to subscribers afterwards.
+	bus := outbox.NewBus(db)
+	transactor := database.NewTransactor(db)
+
+	// Checks run by /health/ready; modules register theirs when built
+	healthChecks := checks.NewRegistry(2 * time.Second)
+	healthChecks.Register(database.Check(db))
+
+	// Modules are built in dependency order, so a module can receive the
+	// modules it depends on.
+	healthModule := health.NewHealthModule(healthChecks)
+
 	return &Container{
 		DB:           db,
-		HealthModule: health.NewHealthModule(),
+		Logger:       logger,
+		Events:       bus,
+		Transactor:   transactor,
+		HealthChecks: healthChecks,
+		HealthModule: healthModule,
 	}
 }
 
@@ -28,5 +58,89 @@ func (c *Container) RegisterRoutes(r *gin.Engine) {
 	api := r.Group("/api/v1")
 	c.HealthModule.RegisterRoutes(api)
 }
-`, data.ModulePath)
+
+func (c *Container) RegisterSubscribers(bus events.Bus) {
+}
+
+// Close stops the modules in the reverse order of their construction, so a
+// module is stopped before the modules it depends on. main.go calls it on
+// shutdown, once the servers have stopped taking requests.
+func (c *Container) Close(ctx context.Context) error {
+	return closeModules(ctx,
+		c.HealthModule,
+	)
+}
+
+%s`, data.ModulePath, data.ModulePath, data.ModulePath, data.ModulePath, data.ModulePath, data.ModulePath, closeModulesFunc)
+}
+
+// InitContainerTemplate is the container `gozilla init` adds to a project
+// that has none: the database and logger the project already builds in its
+// main, and no modules yet.
+func InitContainerTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package container
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Container builds the modules generated by gozilla. Create it in main:
+//
+//	c := container.NewContainer(db, logger)
+//	c.RegisterRoutes(router)
+//	defer c.Close(ctx)
+type Container struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewContainer(db *sql.DB, logger *slog.Logger) *Container {
+	// Modules are built in dependency order, so a module can receive the
+	// modules it depends on.
+	return &Container{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+func (c *Container) RegisterRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	_ = api // until the first module registers its routes
+}
+
+// Close stops the modules in the reverse order of their construction, so a
+// module is stopped before the modules it depends on.
+func (c *Container) Close(ctx context.Context) error {
+	return closeModules(ctx)
+}
+
+%s`, closeModulesFunc)
+}
+
+// closeModulesFunc stops the modules of both containers.
+const closeModulesFunc = `// closeModules calls the Close or Stop method of each module that has one,
+// with or without a context and an error result.
+func closeModules(ctx context.Context, modules ...any) error {
+	var errs []error
+	for _, module := range modules {
+		switch m := module.(type) {
+		case interface{ Close(context.Context) error }:
+			errs = append(errs, m.Close(ctx))
+		case interface{ Close() error }:
+			errs = append(errs, m.Close())
+		case interface{ Stop(context.Context) error }:
+			errs = append(errs, m.Stop(ctx))
+		case interface{ Stop(context.Context) }:
+			m.Stop(ctx)
+		case interface{ Stop() }:
+			m.Stop()
+		}
+	}
+	return errors.Join(errs...)
 }
+`
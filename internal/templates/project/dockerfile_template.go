@@ -0,0 +1,86 @@
+package templates
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// defaultGoImage is the Go toolchain of the image the application is built
+// in, when the project does not say which.
+const defaultGoImage = "1.25"
+
+// goImage returns the tag of the golang image for the go directive of the
+// project: its minor version, so that the image gets patch releases.
+func goImage(data ProjectData) string {
+	parts := strings.Split(data.GoVersion, ".")
+	if len(parts) < 2 {
+		return defaultGoImage
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func DockerfileTemplate(data ProjectData) string {
+	return fmt.Sprintf(`# syntax=docker/dockerfile:1
+
+# Build a static binary, stamped with its version
+FROM golang:%s-alpine AS build
+WORKDIR /src
+
+COPY go.mod go.sum ./
+RUN go mod download
+
+COPY . .
+ARG VERSION=dev
+ARG COMMIT=none
+ARG BUILD_TIME=unknown
+RUN CGO_ENABLED=0 GOOS=linux go build -trimpath \
+    -ldflags "-s -w -X main.version=${VERSION} -X main.commit=${COMMIT} -X main.buildTime=${BUILD_TIME}" \
+    -o /out/api ./cmd/api
+
+# Run it as a non-root user on an image with nothing else in it
+FROM gcr.io/distroless/static-debian12:nonroot
+WORKDIR /app
+
+COPY --from=build /out/api /app/api
+COPY --from=build /src/config*.yaml /app/
+
+ENV ENVIRONMENT=production
+EXPOSE 8080
+USER nonroot:nonroot
+ENTRYPOINT ["/app/api"]
+`, goImage(data))
+}
+
+func DockerignoreTemplate(data ProjectData) string {
+	return `# Keep secrets and build output out of the image
+.git
+.env
+.env.*
+bin/
+*.test
+*.out
+
+# Docker setup
+Dockerfile
+.dockerignore
+docker-compose.yaml
+
+# IDE
+.vscode/
+.idea/
+`
+}
+
+// imageName is the name `make docker-build` tags the image with, which
+// Docker wants in lowercase.
+func imageName(data ProjectData) string {
+	return strings.ToLower(filepath.Base(data.ProjectDir))
+}
+
+// databaseName is the name of the project's PostgreSQL database. It is the
+// image name rather than the module path, whose slashes a database URL
+// cannot hold.
+func databaseName(data ProjectData) string {
+	return imageName(data)
+}
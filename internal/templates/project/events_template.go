@@ -0,0 +1,276 @@
+package templates
+
+import "fmt"
+
+func EventsTemplate(data ProjectData) string {
+	return `package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event is something that happened in a module which other modules may
+// react to. Events are stored and delivered as JSON.
+type Event interface {
+	EventName() string
+}
+
+// Envelope is a stored event as it is delivered to subscribers.
+type Envelope struct {
+	ID         int64
+	Name       string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+}
+
+// Decode unmarshals the payload into the event type the subscriber expects.
+func (e Envelope) Decode(event Event) error {
+	return json.Unmarshal(e.Payload, event)
+}
+
+type Handler func(ctx context.Context, envelope Envelope) error
+
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+type Bus interface {
+	Publisher
+	Subscribe(name string, handler Handler)
+}
+
+// Transactor runs fn in a transaction. Changes made and events published
+// with the ctx passed to fn are committed together, or not at all.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+`
+}
+
+func TransactorTemplate(data ProjectData) string {
+	return `package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Executor runs queries; both *sql.DB and *sql.Tx satisfy it.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Conn returns the transaction carried by ctx, if any, or db.
+func Conn(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction runs fn in a new transaction, or in the one ctx already
+// carries.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+`
+}
+
+func OutboxTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"%s/internal/domain/events"
+	"%s/internal/infrastructure/database"
+)
+
+// Bus stores published events in the outbox table, within the transaction of
+// the change that raised them, and relays them to subscribers afterwards.
+// Delivery is at least once: an event whose subscriber fails is delivered
+// again, to every subscriber, up to MaxAttempts times. After that it stays in
+// the outbox with its last error, for someone to look at.
+type Bus struct {
+	db       *sql.DB
+	mu       sync.RWMutex
+	handlers map[string][]events.Handler
+}
+
+// MaxAttempts is how many times an event is delivered before the relay gives
+// up on it.
+const MaxAttempts = 10
+
+func NewBus(db *sql.DB) *Bus {
+	return &Bus{
+		db:       db,
+		handlers: make(map[string][]events.Handler),
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, evs ...events.Event) error {
+	conn := database.Conn(ctx, b.db)
+	for _, event := range evs {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		if _, err := conn.ExecContext(ctx,
+			"INSERT INTO outbox (name, payload, occurred_at) VALUES ($1, $2, $3)",
+			event.EventName(), payload, time.Now(),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bus) Subscribe(name string, handler events.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Run relays stored events to subscribers every interval until ctx is done.
+func (b *Bus) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.relay(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Outbox relay failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay delivers a batch of pending events. The rows stay locked until the
+// batch is done, so several instances of the application can relay at once.
+func (b *Bus) relay(ctx context.Context) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `+"`"+`
+		SELECT id, name, payload, occurred_at
+		FROM outbox
+		WHERE processed_at IS NULL AND attempts < $1
+		ORDER BY id
+		LIMIT 100
+		FOR UPDATE SKIP LOCKED
+	`+"`"+`, MaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	var pending []events.Envelope
+	for rows.Next() {
+		var e events.Envelope
+		if err := rows.Scan(&e.ID, &e.Name, &e.Payload, &e.OccurredAt); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range pending {
+		if err := b.deliver(ctx, e); err != nil {
+			slog.ErrorContext(ctx, "Failed to handle event", "event", e.Name, "id", e.ID, "error", err)
+			if _, err := tx.ExecContext(ctx,
+				"UPDATE outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2",
+				err.Error(), e.ID,
+			); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE outbox SET processed_at = $1 WHERE id = $2", time.Now(), e.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (b *Bus) deliver(ctx context.Context, e events.Envelope) error {
+	b.mu.RLock()
+	handlers := b.handlers[e.Name]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+`, data.ModulePath, data.ModulePath)
+}
+
+func OutboxMigrationUpTemplate(data ProjectData) string {
+	return `CREATE TABLE IF NOT EXISTS outbox (
+    id           BIGSERIAL PRIMARY KEY,
+    name         TEXT        NOT NULL,
+    payload      JSONB       NOT NULL,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    processed_at TIMESTAMPTZ,
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (id) WHERE processed_at IS NULL;
+`
+}
+
+func OutboxMigrationDownTemplate(data ProjectData) string {
+	return `DROP TABLE IF EXISTS outbox;
+`
+}
@@ -0,0 +1,69 @@
+package templates
+
+func GraphQLHandlerTemplate(data ProjectData) string {
+	return `package graphql
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+type request struct {
+	Query         string                 ` + "`json:\"query\" binding:\"required\"`" + `
+	Variables     map[string]interface{} ` + "`json:\"variables\"`" + `
+	OperationName string                 ` + "`json:\"operationName\"`" + `
+}
+
+// NewHandler merges the queries and mutations registered by every module
+// into one schema and serves it.
+func NewHandler(register func(queries, mutations graphql.Fields)) (gin.HandlerFunc, error) {
+	queries, mutations := graphql.Fields{}, graphql.Fields{}
+	register(queries, mutations)
+
+	config := graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: queries}),
+	}
+	if len(mutations) > 0 {
+		config.Mutation = graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: mutations})
+	}
+
+	schema, err := graphql.NewSchema(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        c.Request.Context(),
+		})
+
+		c.JSON(http.StatusOK, result)
+	}, nil
+}
+`
+}
+
+// GraphQLRoutesBlock serves the schema from the HTTP server's setupRoutes.
+func GraphQLRoutesBlock() string {
+	return `
+	// Serve module queries and mutations
+	graphqlHandler, err := graphql.NewHandler(s.container.RegisterGraphQL)
+	if err != nil {
+		slog.Error("Failed to build GraphQL schema", "error", err)
+		os.Exit(1)
+	}
+	s.router.POST("/graphql", graphqlHandler)
+`
+}
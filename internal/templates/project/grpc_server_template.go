@@ -0,0 +1,89 @@
+package templates
+
+import "fmt"
+
+func GRPCServerTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"%s/internal/infrastructure/config"
+	"%s/internal/infrastructure/container"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	server *grpc.Server
+	config *config.Config
+}
+
+func NewServer(cfg *config.Config, c *container.Container) *Server {
+	server := grpc.NewServer()
+
+	// Register module services
+	c.RegisterGRPC(server)
+	reflection.Register(server)
+
+	return &Server{
+		server: server,
+		config: cfg,
+	}
+}
+
+func (s *Server) Start() error {
+	addr := fmt.Sprintf(":%%s", s.config.GRPCPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("gRPC server starting", "addr", addr)
+	return s.server.Serve(listener)
+}
+
+// Shutdown stops accepting connections and waits for the calls in flight to
+// finish, cutting them off when ctx is done.
+func (s *Server) Shutdown(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
+`, data.ModulePath, data.ModulePath)
+}
+
+// GRPCMainBlock starts the gRPC server next to the HTTP server in main.go.
+func GRPCMainBlock() string {
+	return `	// Start gRPC server
+	grpcServer := grpc.NewServer(cfg, container)
+	go func() {
+		if err := grpcServer.Start(); err != nil {
+			slog.Error("Failed to start gRPC server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+`
+}
+
+// ProtoMakeTarget compiles the .proto files of every module.
+func ProtoMakeTarget() string {
+	return `
+proto:
+	protoc --go_out=. --go_opt=paths=source_relative \
+		--go-grpc_out=. --go-grpc_opt=paths=source_relative \
+		internal/modules/*/infra/proto/*.proto
+`
+}
@@ -0,0 +1,195 @@
+package templates
+
+// HealthChecksTemplate is the port through which modules report whether
+// what they depend on works, for the readiness endpoint.
+func HealthChecksTemplate(data ProjectData) string {
+	return `package checks
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Checker checks one dependency of the application, such as the database
+// or a table a module stores its data in.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type funcChecker struct {
+	name  string
+	check func(context.Context) error
+}
+
+// Func makes a Checker of a function.
+func Func(name string, check func(context.Context) error) Checker {
+	return funcChecker{name: name, check: check}
+}
+
+func (f funcChecker) Name() string { return f.name }
+
+func (f funcChecker) Check(ctx context.Context) error { return f.check(ctx) }
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string  ` + "`json:\"name\"`" + `
+	Status    string  ` + "`json:\"status\"`" + `
+	LatencyMS float64 ` + "`json:\"latency_ms\"`" + `
+	Error     string  ` + "`json:\"error,omitempty\"`" + `
+}
+
+// Report is the outcome of every check: its status is "ok" when all of
+// them passed, "unavailable" otherwise.
+type Report struct {
+	Status string   ` + "`json:\"status\"`" + `
+	Checks []Result ` + "`json:\"checks\"`" + `
+}
+
+// Registry holds the checks of the application. The container registers
+// the database with it, and modules their own checks when they are built.
+type Registry struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+// NewRegistry returns a Registry that gives each check timeout to finish.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+func (r *Registry) Register(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checkers...)
+}
+
+// Drain makes the application report itself unavailable from now on, while
+// it still serves requests, so that load balancers stop sending it traffic
+// before it shuts down.
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Run runs every check at once and reports their outcome, in the order
+// they were registered. Once draining, it runs none and reports the
+// application unavailable.
+func (r *Registry) Run(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{
+			Status: "unavailable",
+			Checks: []Result{{Name: "shutdown", Status: "draining"}},
+		}
+	}
+
+	r.mu.RLock()
+	checkers := r.checkers
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{Status: "ok", Checks: results}
+	for _, result := range results {
+		if result.Status != "ok" {
+			report.Status = "unavailable"
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Checks that ignore ctx are given up on, not waited for
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- checker.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      checker.Name(),
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "failing"
+		result.Error = err.Error()
+	}
+	return result
+}
+`
+}
+
+// HealthChecksTestTemplate tests that a draining registry reports the
+// application unavailable.
+func HealthChecksTestTemplate(data ProjectData) string {
+	return `package checks
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register(Func("database", func(context.Context) error { return nil }))
+
+	if report := registry.Run(context.Background()); report.Status != "ok" {
+		t.Fatalf("Run() before Drain() = %q, want ok", report.Status)
+	}
+
+	registry.Drain()
+
+	report := registry.Run(context.Background())
+	if report.Status != "unavailable" {
+		t.Fatalf("Run() after Drain() = %q, want unavailable", report.Status)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Status != "draining" {
+		t.Errorf("Run() after Drain() checks = %+v, want one draining", report.Checks)
+	}
+}
+`
+}
+
+// DatabaseCheckTemplate checks that the database answers.
+func DatabaseCheckTemplate(data ProjectData) string {
+	return `package database
+
+import (
+	"context"
+	"database/sql"
+
+	"` + data.ModulePath + `/internal/domain/checks"
+)
+
+// Check reports whether the database answers a ping, within the timeout
+// of the registry running it.
+func Check(db *sql.DB) checks.Checker {
+	return checks.Func("database", func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+`
+}
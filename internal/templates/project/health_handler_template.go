@@ -1,25 +1,46 @@
 package templates
 
+import "fmt"
+
 func HealthHandlerTemplate(data ProjectData) string {
-	return `package infra
+	return fmt.Sprintf(`package infra
 
 import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"%s/internal/domain/checks"
 )
 
-type HealthHandler struct{}
+type HealthHandler struct {
+	checks *checks.Registry
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(registry *checks.Registry) *HealthHandler {
+	return &HealthHandler{
+		checks: registry,
+	}
 }
 
-func (h *HealthHandler) Check(c *gin.Context) {
+// Live reports that the process is up. It checks nothing else, so that an
+// outage of the database does not get the process restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
-		"message": "Service is healthy",
 	})
 }
-`
+
+// Ready runs the registered checks and answers 503 unless every one of
+// them passes, so that no traffic is sent while a dependency is down.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checks.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+`, data.ModulePath)
 }
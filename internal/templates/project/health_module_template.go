@@ -6,16 +6,20 @@ func HealthModuleTemplate(data ProjectData) string {
 	return fmt.Sprintf(`package health
 
 import (
-	"%s/internal/modules/health/infra"
 	"github.com/gin-gonic/gin"
+
+	"%s/internal/domain/checks"
+	"%s/internal/modules/health/infra"
 )
 
 type HealthModule struct {
 	Handler *infra.HealthHandler
 }
 
-func NewHealthModule() *HealthModule {
-	handler := infra.NewHealthHandler()
+// NewHealthModule serves the checks registered with registry: the database,
+// and those of the modules built by the container.
+func NewHealthModule(registry *checks.Registry) *HealthModule {
+	handler := infra.NewHealthHandler(registry)
 	return &HealthModule{
 		Handler: handler,
 	}
@@ -24,5 +28,5 @@ func NewHealthModule() *HealthModule {
 func (m *HealthModule) RegisterRoutes(r *gin.RouterGroup) {
 	infra.RegisterRoutes(r, m.Handler)
 }
-`, data.ModulePath)
+`, data.ModulePath, data.ModulePath)
 }
@@ -8,7 +8,9 @@ import "github.com/gin-gonic/gin"
 func RegisterRoutes(r *gin.RouterGroup, handler *HealthHandler) {
 	health := r.Group("/health")
 	{
-		health.GET("", handler.Check)
+		health.GET("", handler.Ready)
+		health.GET("/live", handler.Live)
+		health.GET("/ready", handler.Ready)
 	}
 }
 `
@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// K8sData describes the project to the Kubernetes manifests of `gozilla add
+// k8s`.
+type K8sData struct {
+	Name        string // name of every resource, and of the image
+	Port        string
+	GRPCPort    string // empty without a gRPC server
+	LivePath    string
+	ReadyPath   string
+	Metrics     bool // whether Prometheus can scrape /metrics
+	GracePeriod int  // seconds, longer than SHUTDOWN_DRAIN_PERIOD + SHUTDOWN_TIMEOUT
+	Config      []K8sValue
+	Secrets     []K8sValue
+}
+
+// K8sValue is an environment variable of the application.
+type K8sValue struct {
+	Key   string
+	Value string
+}
+
+func K8sKustomizationTemplate(data K8sData) string {
+	return fmt.Sprintf(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+
+# Base manifests of %s. Put what differs between clusters in overlays,
+# and pick the image with: kustomize edit set image %s=<registry>/%s:<tag>
+resources:
+  - configmap.yaml
+  - secret.yaml
+  - deployment.yaml
+  - service.yaml
+
+labels:
+  - pairs:
+      app.kubernetes.io/name: %s
+    includeSelectors: true
+
+images:
+  - name: %s
+    newTag: latest
+`, data.Name, data.Name, data.Name, data.Name, data.Name)
+}
+
+func K8sDeploymentTemplate(data K8sData) string {
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app.kubernetes.io/component: api
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/component: api%s
+    spec:
+      # Longer than SHUTDOWN_DRAIN_PERIOD + SHUTDOWN_TIMEOUT, so that the pod
+      # leaves the Service and the requests in flight can finish
+      terminationGracePeriodSeconds: %d
+      securityContext:
+        runAsNonRoot: true
+      containers:
+        - name: api
+          image: %s
+          ports:
+            - name: http
+              containerPort: %s%s
+          envFrom:
+            - configMapRef:
+                name: %s-config
+            - secretRef:
+                name: %s-secrets
+          livenessProbe:
+            httpGet:
+              path: %s
+              port: http
+            periodSeconds: 10
+            failureThreshold: 3
+          readinessProbe:
+            httpGet:
+              path: %s
+              port: http
+            periodSeconds: 5
+            failureThreshold: 2
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 256Mi
+          securityContext:
+            allowPrivilegeEscalation: false
+            readOnlyRootFilesystem: true
+            capabilities:
+              drop: ["ALL"]
+`, data.Name, k8sMetricsAnnotations(data), data.GracePeriod, data.Name,
+		data.Port, k8sGRPCContainerPort(data),
+		data.Name, data.Name,
+		data.LivePath, data.ReadyPath)
+}
+
+func K8sServiceTemplate(data K8sData) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: %s
+spec:
+  selector:
+    app.kubernetes.io/component: api
+  ports:
+    - name: http
+      port: 80
+      targetPort: http%s
+`, data.Name, k8sGRPCServicePort(data))
+}
+
+// K8sConfigMapTemplate lists the settings read in config.Load, except the
+// secret ones.
+func K8sConfigMapTemplate(data K8sData) string {
+	return fmt.Sprintf(`# Generated from internal/infrastructure/config by gozilla add k8s, which
+# keeps the values set here when it is run again after settings change.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s-config
+data:
+%s`, data.Name, k8sValues(data.Config))
+}
+
+// K8sSecretTemplate lists the secret settings with placeholder values.
+func K8sSecretTemplate(data K8sData) string {
+	return fmt.Sprintf(`# Placeholders: keep the real values out of git, for instance with
+#   kubectl create secret generic %s-secrets --from-literal=DATABASE_URL=...
+# or a secret manager. gozilla add k8s adds the secret settings of the
+# config here when it is run again.
+apiVersion: v1
+kind: Secret
+metadata:
+  name: %s-secrets
+type: Opaque
+stringData:
+%s`, data.Name, data.Name, k8sValues(data.Secrets))
+}
+
+func k8sValues(values []K8sValue) string {
+	if len(values) == 0 {
+		return "  {}\n"
+	}
+	var b strings.Builder
+	for _, v := range values {
+		fmt.Fprintf(&b, "  %s: %s\n", v.Key, strconv.Quote(v.Value))
+	}
+	return b.String()
+}
+
+func k8sMetricsAnnotations(data K8sData) string {
+	if !data.Metrics {
+		return ""
+	}
+	return fmt.Sprintf(`
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "%s"
+        prometheus.io/path: /metrics`, data.Port)
+}
+
+func k8sGRPCContainerPort(data K8sData) string {
+	if data.GRPCPort == "" {
+		return ""
+	}
+	return fmt.Sprintf(`
+            - name: grpc
+              containerPort: %s`, data.GRPCPort)
+}
+
+func k8sGRPCServicePort(data K8sData) string {
+	if data.GRPCPort == "" {
+		return ""
+	}
+	return `
+    - name: grpc
+      port: 9090
+      targetPort: grpc`
+}
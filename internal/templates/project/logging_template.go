@@ -0,0 +1,66 @@
+package templates
+
+import "fmt"
+
+func LoggingTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"%s/internal/infrastructure/config"
+)
+
+// New builds the application logger from the LOG_LEVEL (debug, info, warn,
+// error) and LOG_FORMAT (json, text) settings. Records logged with a
+// context carry the attributes added to it with WithAttrs.
+func New(cfg *config.Config) *slog.Logger {
+	level := slog.LevelInfo
+	_ = level.UnmarshalText([]byte(cfg.LogLevel))
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.LogFormat, "text") {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	}
+
+	return slog.New(contextHandler{handler})
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a copy of ctx whose log records carry attrs, such as
+// the request ID the HTTP middleware adds.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, attrsKey{}, append(slices.Clip(existing), attrs...))
+}
+
+// contextHandler adds the attributes of WithAttrs to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+`, data.ModulePath)
+}
@@ -6,43 +6,109 @@ type ProjectData struct {
 	ProjectName string
 	ModulePath  string
 	ProjectDir  string
+	// Observability adds OpenTelemetry tracing and Prometheus metrics
+	// (gozilla new --observability).
+	Observability bool
+	// CI is the CI service workflows are generated for: "github", "gitlab"
+	// or none (gozilla new --ci).
+	CI string
+	// GoVersion is the go directive of go.mod, such as 1.25.3, which the
+	// Docker and CI images follow.
+	GoVersion string
 }
 
 func MainGoTemplate(data ProjectData) string {
 	return fmt.Sprintf(`package main
 
 import (
-    "log"
+    "context"
+    "log/slog"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
 
     "%s/internal/infrastructure/config"
     "%s/internal/infrastructure/container"
     "%s/internal/infrastructure/database"
     "%s/internal/infrastructure/http"
+    "%s/internal/infrastructure/logging"%s
     _ "github.com/lib/pq"
 )
 
+// Set at build time by make build and the Dockerfile
+var (
+    version   = "dev"
+    commit    = "none"
+    buildTime = "unknown"
+)
+
 func main() {
+    // Cancelled on SIGINT/SIGTERM, which starts the shutdown
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
     // Load configuration
     cfg, err := config.Load()
     if err != nil {
-        log.Fatalf("Failed to load config: %%v", err)
+        slog.Error("Failed to load config", "error", err)
+        os.Exit(1)
     }
 
-    // Initialize database
+    // Initialize logger
+    logger := logging.New(cfg)
+    slog.SetDefault(logger)
+    logger.Info("Starting", "version", version, "commit", commit, "built", buildTime, "environment", cfg.Environment)
+
+%s    // Initialize database
     db, err := database.NewConnection(cfg.DatabaseURL)
     if err != nil {
-        log.Fatalf("Failed to connect to database: %%v", err)
+        slog.Error("Failed to connect to database", "error", err)
+        os.Exit(1)
     }
     defer db.Close()
 
     // Initialize DI container
-    container := container.NewContainer(db)
+    container := container.NewContainer(cfg, db, logger)
+
+    // Relay domain events from the outbox to subscribers
+    container.RegisterSubscribers(container.Events)
+    go container.Events.Run(ctx, time.Second)
 
     // Start HTTP server
     server := http.NewServer(cfg, container)
-    if err := server.Start(); err != nil {
-        log.Fatalf("Failed to start server: %%v", err)
+    go func() {
+        if err := server.Start(); err != nil {
+            slog.Error("Failed to start server", "error", err)
+            os.Exit(1)
+        }
+    }()
+
+    <-ctx.Done()
+    stop() // a second signal kills the process
+
+    // Keep serving while /health/ready answers 503, so that load balancers
+    // stop sending requests before the server stops taking them
+    if cfg.ShutdownDrainPeriod > 0 {
+        container.HealthChecks.Drain()
+        slog.Info("Draining", "period", cfg.ShutdownDrainPeriod)
+        time.Sleep(cfg.ShutdownDrainPeriod)
+    }
+
+    slog.Info("Shutting down", "timeout", cfg.ShutdownTimeout)
+
+    // Stop taking requests and let those in flight finish, then stop the
+    // modules; what was started first is stopped last
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+    defer cancel()
+
+    if err := server.Shutdown(shutdownCtx); err != nil {
+        slog.Error("HTTP server shutdown failed", "error", err)
+    }
+    if err := container.Close(shutdownCtx); err != nil {
+        slog.Error("Failed to stop modules", "error", err)
     }
 }
-`, data.ModulePath, data.ModulePath, data.ModulePath, data.ModulePath)
+`, data.ModulePath, data.ModulePath, data.ModulePath, data.ModulePath, data.ModulePath, telemetryMainImport(data),
+		telemetryMainBlock(data))
 }
@@ -1,29 +1,53 @@
 package templates
 
 func MakefileTemplate(data ProjectData) string {
-	return `.PHONY: run build test docker-up docker-down migrate-up migrate-down
+	return `.PHONY: run build test docker-build docker-up docker-app docker-down migrate-up migrate-down migrate-check
+
+# Build info, stamped into the binary and the image
+VERSION ?= $(shell git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT ?= $(shell git rev-parse --short HEAD 2>/dev/null || echo none)
+BUILD_TIME ?= $(shell date -u +%Y-%m-%dT%H:%M:%SZ)
+LDFLAGS := -X main.version=$(VERSION) -X main.commit=$(COMMIT) -X main.buildTime=$(BUILD_TIME)
+IMAGE ?= ` + imageName(data) + `
 
 run:
 	go run cmd/api/main.go
 
 build:
-	go build -o bin/api cmd/api/main.go
+	go build -ldflags "$(LDFLAGS)" -o bin/api ./cmd/api
 
 test:
 	go test -v ./...
 
+docker-build:
+	docker build \
+		--build-arg VERSION=$(VERSION) \
+		--build-arg COMMIT=$(COMMIT) \
+		--build-arg BUILD_TIME=$(BUILD_TIME) \
+		-t $(IMAGE):$(VERSION) -t $(IMAGE):latest .
+
 docker-up:
 	docker-compose up -d
 
+# Run the application in a container too, next to the database
+docker-app:
+	docker-compose --profile app up -d --build
+
 docker-down:
-	docker-compose down
+	docker-compose --profile app down
 
 migrate-up:
-	@echo "Migrations not configured yet"
+	@for f in $$(ls migrations/*.up.sql); do \
+		echo "Applying $$f"; \
+		docker-compose exec -T postgres psql -U postgres -d ` + databaseName(data) + ` -v ON_ERROR_STOP=1 < $$f || exit 1; \
+	done
 
 migrate-down:
-	@echo "Migrations not configured yet"
-
+	@for f in $$(ls -r migrations/*.down.sql); do \
+		echo "Reverting $$f"; \
+		docker-compose exec -T postgres psql -U postgres -d ` + databaseName(data) + ` -v ON_ERROR_STOP=1 < $$f || exit 1; \
+	done
+` + MigrateCheckMakeTarget() + `
 clean:
 	rm -rf bin/
 `
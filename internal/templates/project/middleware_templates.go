@@ -0,0 +1,313 @@
+package templates
+
+import "fmt"
+
+func RequestIDMiddlewareTemplate(data ProjectData) string {
+	return `package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader carries the request ID in requests and responses.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key holding the request ID.
+	RequestIDKey = "request_id"
+)
+
+type requestIDKey struct{}
+
+// RequestID tags every request with an ID, taken from the X-Request-ID
+// header when the client or a proxy sent one, and echoes it back.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDKey{}, id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the ID of the request ctx belongs to.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+`
+}
+
+func LoggerMiddlewareTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"%s/internal/infrastructure/logging"
+)
+
+// Logger adds the request ID and route to the request context, so that
+// everything logged while serving the request carries them, and writes one
+// structured log line per request once it is served.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		ctx := logging.WithAttrs(c.Request.Context(),
+			slog.String("request_id", c.GetString(RequestIDKey)),
+			slog.String("route", c.FullPath()),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+`, data.ModulePath)
+}
+
+func RecoveryMiddlewareTemplate(data ProjectData) string {
+	return `package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a JSON 500 response and logs it
+// with the stack trace.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			// The client went away or the handler gave up on the response
+			if e, ok := err.(error); ok && errors.Is(e, http.ErrAbortHandler) {
+				panic(err)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				slog.Any("panic", err),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":      "internal server error",
+				"request_id": c.GetString(RequestIDKey),
+			})
+		}()
+
+		c.Next()
+	}
+}
+`
+}
+
+func CORSMiddlewareTemplate(data ProjectData) string {
+	return `package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	corsMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsHeaders = []string{"Authorization", "Content-Type", RequestIDHeader}
+)
+
+// CORS lets browsers on the allowed origins call the API. "*" allows any
+// origin; with no origins, cross-origin requests get no CORS headers and
+// are blocked by the browser.
+func CORS(allowedOrigins []string) gin.HandlerFunc {
+	anyOrigin := slices.Contains(allowedOrigins, "*")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !anyOrigin && !slices.Contains(allowedOrigins, origin) {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Expose-Headers", RequestIDHeader)
+
+		// Preflight
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", strings.Join(corsMethods, ", "))
+			c.Header("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+`
+}
+
+func TimeoutMiddlewareTemplate(data ProjectData) string {
+	return `package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout puts a deadline on the request context, so that database calls
+// and other context-aware work are cancelled once it passes. A handler
+// that ran out of time without responding gets a 504.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		}
+	}
+}
+`
+}
+
+func BodyLimitMiddlewareTemplate(data ProjectData) string {
+	return `package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit rejects request bodies larger than limit bytes with a 413.
+// Bodies of unknown length are cut off at the limit while they are read.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+`
+}
+
+func SecurityHeadersMiddlewareTemplate(data ProjectData) string {
+	return `package middleware
+
+import "github.com/gin-gonic/gin"
+
+// SecurityHeaders sets the response headers that keep browsers from
+// sniffing, framing or leaking API responses. HSTS is only sent over HTTPS.
+func SecurityHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		}
+
+		c.Next()
+	}
+}
+`
+}
+
+// CustomMiddlewareTemplate is the middleware scaffolded by
+// `gozilla generate middleware <name>`.
+func CustomMiddlewareTemplate(data ProjectData, name string) string {
+	return fmt.Sprintf(`package middleware
+
+import "github.com/gin-gonic/gin"
+
+// %s runs around every route it is registered on.
+func %s() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Runs before the handler; c.AbortWithStatusJSON rejects the request.
+
+		c.Next()
+
+		// Runs after the handler, with the response status in c.Writer.Status().
+	}
+}
+`, name, name)
+}
@@ -26,16 +26,29 @@ Generated with [Gozilla](https://github.com/pierslabs/gozilla)
 make docker-up
 `+"```"+`
 
-2. Run the application:
+2. Create the tables, including the event outbox:
+`+"```bash"+`
+make migrate-up
+`+"```"+`
+
+3. Run the application:
 `+"```bash"+`
 make run
 `+"```"+`
 
 The API will be available at http://localhost:8080
 
+To run it in a container instead, build the image and start it next to the
+database (stop `+"`make run`"+` first, both listen on 8080):
+`+"```bash"+`
+make docker-build
+make docker-app
+`+"```"+`
+
 ### API Endpoints
 
-- `+"`GET /api/v1/health`"+` - Health check
+- `+"`GET /api/v1/health/live`"+` - Liveness: the process is up
+- `+"`GET /api/v1/health/ready`"+` - Readiness: the database and every module's checks pass (503 otherwise)
 
 ## Development
 
@@ -45,6 +58,19 @@ The API will be available at http://localhost:8080
 gozilla generate module users
 `+"```"+`
 
+### Publish and handle events
+
+Create, update and delete use cases publish `+"`<Entity>Created`"+`,
+`+"`<Entity>Updated`"+` and `+"`<Entity>Deleted`"+` events. They are stored in the
+`+"`outbox`"+` table in the same transaction as the change, then relayed to
+subscribers. An event whose subscriber keeps failing is given up on after
+`+"`outbox.MaxAttempts`"+` tries; its `+"`attempts`"+` and `+"`last_error`"+` stay in
+the table. Add a custom event and a subscriber with:
+
+`+"```bash"+`
+gozilla generate event orders OrderShipped --subscriber notifications
+`+"```"+`
+
 ### Run tests
 
 `+"```bash"+`
@@ -64,14 +90,17 @@ make build
 ├── cmd/api/                    # Application entry point
 ├── internal/
 │   ├── domain/                # Shared domain
+│   │   └── events/           # Event bus abstraction
 │   ├── infrastructure/        # Infrastructure layer
 │   │   ├── config/           # Configuration
 │   │   ├── database/         # Database connection
 │   │   ├── http/             # HTTP server
+│   │   ├── outbox/           # Transactional outbox and relay
 │   │   └── container/        # DI container
 │   └── modules/              # Feature modules
 │       └── health/           # Health check module
 ├── migrations/               # Database migrations
+├── Dockerfile                # Application image
 └── docker-compose.yaml       # Docker setup
 `+"```"+`
 
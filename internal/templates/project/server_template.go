@@ -6,17 +6,23 @@ func ServerTemplate(data ProjectData) string {
 	return fmt.Sprintf(`package http
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 
 	"%s/internal/infrastructure/config"
 	"%s/internal/infrastructure/container"
+	"%s/internal/infrastructure/http/middleware"%s
 	"github.com/gin-gonic/gin"
 )
 
 type Server struct {
-	router    *gin.Engine
-	config    *config.Config
-	container *container.Container
+	router     *gin.Engine
+	config     *config.Config
+	container  *container.Container
+	httpServer *http.Server
 }
 
 func NewServer(cfg *config.Config, c *container.Container) *Server {
@@ -24,12 +30,19 @@ func NewServer(cfg *config.Config, c *container.Container) *Server {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
+	router := gin.New()
 
 	s := &Server{
 		router:    router,
 		config:    cfg,
 		container: c,
+		httpServer: &http.Server{
+			Addr:         fmt.Sprintf(":%%s", cfg.Port),
+			Handler:      router,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
 	}
 
 	s.setupRoutes()
@@ -38,14 +51,36 @@ func NewServer(cfg *config.Config, c *container.Container) *Server {
 }
 
 func (s *Server) setupRoutes() {
+	// Middleware run in this order, around every route
+	s.router.Use(
+		middleware.RequestID(),%s
+		middleware.Logger(s.container.Logger),
+		middleware.Recovery(s.container.Logger),
+		middleware.SecurityHeaders(),
+		middleware.CORS(s.config.CORSAllowedOrigins),
+		middleware.BodyLimit(s.config.MaxBodyBytes),
+		middleware.Timeout(s.config.RequestTimeout),
+	)
+%s
 	// Register module routes
 	s.container.RegisterRoutes(s.router)
 }
 
+// Start serves HTTP until Shutdown is called.
 func (s *Server) Start() error {
-	addr := fmt.Sprintf(":%%s", s.config.Port)
-	fmt.Printf("Server starting on %%s\n", addr)
-	return s.router.Run(addr)
+	slog.Info("HTTP server starting", "addr", s.httpServer.Addr)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for the requests in
+// flight to finish, until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
-`, data.ModulePath, data.ModulePath)
+`, data.ModulePath, data.ModulePath, data.ModulePath, telemetryServerImport(data),
+		telemetryMiddleware(data),
+		telemetryRoutes(data))
 }
@@ -0,0 +1,262 @@
+package templates
+
+import "fmt"
+
+func TelemetryTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"%s/internal/infrastructure/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Setup installs the global OpenTelemetry tracer and meter providers, which
+// otel.Tracer and otel.Meter hand out everywhere in the application.
+//
+// Traces are exported as set by OTEL_TRACES_EXPORTER: "stdout" (the default,
+// which works offline), "otlp" (over HTTP to OTEL_EXPORTER_OTLP_ENDPOINT,
+// http://localhost:4318 by default) or "none". Metrics are served in the
+// Prometheus format by MetricsHandler.
+//
+// The returned function flushes pending spans and stops the providers.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resource: %%w", err)
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.TracesExporter {
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER '%%s' (expected stdout, otlp or none)", cfg.TracesExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %%w", err)
+	}
+
+	traceOpts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if exporter != nil {
+		traceOpts = append(traceOpts, sdktrace.WithBatcher(exporter))
+	}
+	tracerProvider := sdktrace.NewTracerProvider(traceOpts...)
+
+	// Registers with the default Prometheus registry, served by MetricsHandler
+	reader, err := otelprometheus.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics exporter: %%w", err)
+	}
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(reader),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}, nil
+}
+
+// MetricsHandler serves the application metrics, along with the Go runtime
+// and process metrics, for Prometheus to scrape.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+`, data.ModulePath)
+}
+
+func TelemetryMiddlewareTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"%s/internal/infrastructure/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "%s/internal/infrastructure/http/middleware"
+
+// Telemetry traces every request in a server span, continuing the trace of
+// the caller when it sent a traceparent header, and records the RED metrics
+// of each route: requests by status code (rate and errors) and duration.
+// Everything logged while serving the request carries the trace ID.
+func Telemetry() gin.HandlerFunc {
+	tracer := otel.Tracer(instrumentationName)
+	meter := otel.Meter(instrumentationName)
+
+	requests, _ := meter.Int64Counter("http.server.requests",
+		metric.WithDescription("HTTP requests served, by route and status code."),
+	)
+	duration, _ := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Time taken to serve HTTP requests."),
+		metric.WithUnit("s"),
+	)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// Requests matching no route share one label, to bound the metrics
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = logging.WithAttrs(ctx,
+				slog.String("trace_id", sc.TraceID().String()),
+				slog.String("span_id", sc.SpanID().String()),
+			)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		attrs := metric.WithAttributes(
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		requests.Add(ctx, 1, attrs)
+		duration.Record(ctx, time.Since(start).Seconds(), attrs)
+	}
+}
+`, data.ModulePath, data.ModulePath)
+}
+
+// The telemetry helpers below add tracing and metrics to the project files
+// of `gozilla new --observability`.
+
+func telemetryMainImport(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return fmt.Sprintf("\n    \"%s/internal/infrastructure/telemetry\"", data.ModulePath)
+}
+
+func telemetryMainBlock(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return `    // Initialize tracing and metrics
+    shutdownTelemetry, err := telemetry.Setup(ctx, cfg)
+    if err != nil {
+        slog.Error("Failed to set up telemetry", "error", err)
+        os.Exit(1)
+    }
+    defer shutdownTelemetry(context.Background())
+
+`
+}
+
+func telemetryConfigFields(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return `
+
+	// Telemetry
+	ServiceName    string
+	TracesExporter string`
+}
+
+func telemetryConfigValues(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return fmt.Sprintf(`
+
+		ServiceName:    s.getEnv("OTEL_SERVICE_NAME", "%s"),
+		TracesExporter: s.getEnv("OTEL_TRACES_EXPORTER", "stdout"),`, data.ProjectDir)
+}
+
+func telemetryEnv(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return fmt.Sprintf(`
+# Telemetry: traces go to stdout, otlp (OTEL_EXPORTER_OTLP_ENDPOINT) or
+# none; metrics are served on /metrics
+OTEL_SERVICE_NAME=%s
+OTEL_TRACES_EXPORTER=stdout
+`, data.ProjectDir)
+}
+
+func telemetryServerImport(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return fmt.Sprintf("\n\t\"%s/internal/infrastructure/telemetry\"", data.ModulePath)
+}
+
+func telemetryMiddleware(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return "\n\t\tmiddleware.Telemetry(),"
+}
+
+func telemetryRoutes(data ProjectData) string {
+	if !data.Observability {
+		return ""
+	}
+	return `
+	// Prometheus metrics
+	s.router.GET("/metrics", gin.WrapH(telemetry.MetricsHandler()))
+`
+}
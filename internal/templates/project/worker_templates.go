@@ -0,0 +1,244 @@
+package templates
+
+import "fmt"
+
+func QueueTemplate(data ProjectData) string {
+	return `package queue
+
+import "context"
+
+// Message is a job taken from a queue.
+type Message struct {
+	Topic string
+	Body  []byte
+}
+
+type Handler func(ctx context.Context, msg Message) error
+
+type Publisher interface {
+	Publish(ctx context.Context, topic string, body []byte) error
+}
+
+// Consumer delivers the jobs published on topic to handler until ctx is
+// done. A job being handled when ctx is done is allowed to finish.
+type Consumer interface {
+	Consume(ctx context.Context, topic string, handler Handler) error
+}
+
+// Queue is implemented by each broker adapter in
+// internal/infrastructure/queue.
+type Queue interface {
+	Publisher
+	Consumer
+}
+`
+}
+
+func MemoryQueueTemplate(data ProjectData) string {
+	return fmt.Sprintf(`package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"%s/internal/domain/queue"
+)
+
+// Queue keeps jobs in memory, in a buffered channel per topic. Jobs are lost
+// when the process exits; use a broker adapter for jobs that must survive a
+// restart.
+type Queue struct {
+	mu     sync.Mutex
+	size   int
+	topics map[string]chan queue.Message
+}
+
+func NewQueue(size int) *Queue {
+	return &Queue{
+		size:   size,
+		topics: make(map[string]chan queue.Message),
+	}
+}
+
+func (q *Queue) topic(name string) chan queue.Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ch, ok := q.topics[name]
+	if !ok {
+		ch = make(chan queue.Message, q.size)
+		q.topics[name] = ch
+	}
+	return ch
+}
+
+// Publish blocks while the topic's buffer is full.
+func (q *Queue) Publish(ctx context.Context, topic string, body []byte) error {
+	select {
+	case q.topic(topic) <- queue.Message{Topic: topic, Body: body}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) Consume(ctx context.Context, topic string, handler queue.Handler) error {
+	messages := q.topic(topic)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-messages:
+			if err := handler(context.WithoutCancel(ctx), msg); err != nil {
+				slog.ErrorContext(ctx, "Failed to handle job", "topic", topic, "error", err)
+			}
+		}
+	}
+}
+`, data.ModulePath)
+}
+
+func WorkerRunnerTemplate(data ProjectData) string {
+	return `package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Worker is a background process run next to the HTTP server.
+type Worker interface {
+	Run(ctx context.Context) error
+}
+
+// Group is a set of running workers.
+type Group struct {
+	wg sync.WaitGroup
+}
+
+// Start runs each worker in its own goroutine until ctx is done.
+func Start(ctx context.Context, workers ...Worker) *Group {
+	g := &Group{}
+	for _, w := range workers {
+		g.wg.Add(1)
+		go func(w Worker) {
+			defer g.wg.Done()
+			if err := w.Run(ctx); err != nil {
+				slog.Error("Worker stopped", "error", err)
+			}
+		}(w)
+	}
+	return g
+}
+
+// Wait blocks until every worker has returned.
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
+
+// WaitContext is Wait bounded by ctx: a job stuck past the shutdown
+// timeout is abandoned rather than holding up the exit.
+func (g *Group) WaitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+`
+}
+
+func WorkerTemplate(data ProjectData, name string) string {
+	return fmt.Sprintf(`package %s
+
+import (
+	"context"
+	"log/slog"
+
+	"%s/internal/domain/queue"
+)
+
+// Topic is the queue topic the worker consumes. Enqueue jobs with
+// queue.Publisher.Publish(ctx, %s.Topic, body).
+const Topic = "%s"
+
+type Worker struct {
+	consumer queue.Consumer
+	logger   *slog.Logger
+}
+
+func NewWorker(consumer queue.Consumer, logger *slog.Logger) *Worker {
+	return &Worker{
+		consumer: consumer,
+		logger:   logger,
+	}
+}
+
+// Run handles jobs until ctx is done.
+func (w *Worker) Run(ctx context.Context) error {
+	w.logger.InfoContext(ctx, "Worker started", "topic", Topic)
+	return w.consumer.Consume(ctx, Topic, w.handle)
+}
+
+func (w *Worker) handle(ctx context.Context, msg queue.Message) error {
+	// Process the job here.
+	w.logger.InfoContext(ctx, "Job received", "topic", Topic, "bytes", len(msg.Body))
+	return nil
+}
+`, name, data.ModulePath, name, name)
+}
+
+// WorkersStartBlock runs the container's workers from main.go until ctx,
+// cancelled on SIGINT/SIGTERM, is done. main.go waits for them on shutdown.
+func WorkersStartBlock(ctx string) string {
+	return fmt.Sprintf(`	// Run background workers until shutdown
+	workers := worker.Start(%s, container.Workers()...)
+
+`, ctx)
+}
+
+// WorkersWaitBlock waits for the workers after the HTTP server shut down,
+// within the same shutdown timeout.
+func WorkersWaitBlock(shutdownCtx string) string {
+	return fmt.Sprintf(`	if err := workers.WaitContext(%s); err != nil {
+		slog.Error("Workers did not finish in time", "error", err)
+	}`, shutdownCtx)
+}
+
+// WorkersMainBlock runs the container's workers from main.go and stops them
+// on SIGINT/SIGTERM, in projects generated before graceful shutdown.
+func WorkersMainBlock() string {
+	return `	// Run background workers until SIGINT/SIGTERM, then let them finish
+	// the job at hand before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	workers := worker.Start(ctx, container.Workers()...)
+	go func() {
+		<-ctx.Done()
+		workers.Wait()
+		os.Exit(0)
+	}()
+
+`
+}
+
+// WorkersMethod lists the workers main.go runs.
+func WorkersMethod(recv, field string) string {
+	return fmt.Sprintf(`
+// Workers are run by main.go next to the HTTP server.
+func (%s *Container) Workers() []worker.Worker {
+	return []worker.Worker{
+		%s.%s,
+	}
+}
+`, recv, recv, field)
+}